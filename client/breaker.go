@@ -0,0 +1,129 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type BreakerSettings struct {
+	// FailureThreshold consecutive failures open the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+	// HalfOpenProbes requests are let through while half-open; the breaker
+	// closes once they all succeed.
+	HalfOpenProbes int
+}
+
+// Breaker is a closed/open/half-open circuit breaker for one processor.
+type Breaker struct {
+	Settings  BreakerSettings
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+func NewBreaker(settings BreakerSettings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 1
+	}
+	if settings.HalfOpenProbes <= 0 {
+		settings.HalfOpenProbes = 1
+	}
+	return &Breaker{Settings: settings}
+}
+
+// Allow reports whether a request may be sent now. While half-open it hands
+// out at most HalfOpenProbes permits.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.Settings.OpenTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probes = 0
+		b.successes = 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.Settings.HalfOpenProbes {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.Settings.FailureThreshold {
+			b.open()
+		}
+	case BreakerHalfOpen:
+		if !success {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.Settings.HalfOpenProbes {
+			b.state = BreakerClosed
+			b.failures = 0
+		}
+	}
+}
+
+func (b *Breaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+}
+
+// State returns the current state, reporting an open breaker whose timeout
+// has passed as half-open.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.Settings.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
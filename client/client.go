@@ -1,82 +1,72 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
 	"net/url"
 	"rb2025-v3/model"
 	"time"
 
 	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+	"github.com/valyala/fasthttp"
 )
 
 type Client struct {
-	DefaultUrl  string
-	FallbackUrl string
-	HealthUrl   string
-	Client      *http.Client
-}
-
-func NewClient(defaultUrl, fallbackUrl, healthUrl string) *Client {
-	transport := &http.Transport{
-		MaxIdleConns:        2000, // Increase for high concurrency
-		MaxIdleConnsPerHost: 2000, // Increase for high concurrency
-		IdleConnTimeout:     90 * time.Second,
-		DisableKeepAlives:   false,
-		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,  // Lower timeout for faster failover
-			KeepAlive: 60 * time.Second, // Longer keepalive for connection reuse
-		}).DialContext,
-		// Optional: tune TLSHandshakeTimeout, ExpectContinueTimeout, etc.
-	}
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   5 * time.Second, // Lower timeout for faster error returns
-	}
-	return &Client{
-		DefaultUrl:  defaultUrl,
-		FallbackUrl: fallbackUrl,
-		HealthUrl:   healthUrl,
-		Client:      client,
-	}
-}
-
-// SendPayment tries default first, then fallback
-func (c *Client) SendPayment(event model.PaymentEvent, serviceHealth model.ServiceHealthResponse) (int, error) {
-	if serviceHealth.DefaultHealth && serviceHealth.FallbackHealth {
-		firstUrl := c.DefaultUrl
-		secondUrl := c.FallbackUrl
-		firstProcessor := 0
-		secondProcessor := 1
-		if serviceHealth.FallbackMinResponse < serviceHealth.DefaultMinResponse {
-			secondUrl = c.DefaultUrl
-			firstProcessor = 1
-			firstUrl = c.FallbackUrl
-			secondProcessor = 0
-		}
-		if c.PostJSON(firstUrl, event) {
-			return firstProcessor, nil
-		}
-		if c.PostJSON(secondUrl, event) {
-			return secondProcessor, nil
-		}
-	} else if serviceHealth.DefaultHealth {
-		if c.PostJSON(c.DefaultUrl, event) {
-			return 0, nil
-		}
-	} else if serviceHealth.FallbackHealth {
-		if c.PostJSON(c.FallbackUrl, event) {
-			return 1, nil
+	Processors []Processor
+	HealthUrl  string
+	// Client serves the calls to peers and HEALTH_URL.
+	Client *fasthttp.Client
+	// Timeout bounds every request made by the client.
+	Timeout time.Duration
+	// Prober, when set, answers ServiceHealth in-process instead of
+	// HealthUrl.
+	Prober *Prober
+	// Windows holds the observed traffic per processor.
+	Windows []*Window
+	// Breakers holds a circuit breaker per processor.
+	Breakers []*Breaker
+	// Limiters holds an adaptive concurrency limit per processor.
+	Limiters []*Limiter
+	// hosts keeps a connection pool per processor, and paymentUrls their
+	// POST /payments URL, both indexed by processor number.
+	hosts       []*fasthttp.HostClient
+	paymentUrls []string
+}
+
+func NewClient(processors []Processor, healthUrl string, statsWindow time.Duration, breaker BreakerSettings, limiter LimiterSettings) (*Client, error) {
+	c := &Client{
+		Processors: processors,
+		HealthUrl:  healthUrl,
+		Client: &fasthttp.Client{
+			MaxConnsPerHost:     2000,
+			MaxIdleConnDuration: 90 * time.Second,
+		},
+		Timeout: 5 * time.Second,
+	}
+	for _, processor := range processors {
+		u, err := url.Parse(processor.Url)
+		if err != nil {
+			return nil, fmt.Errorf("processor %s: %w", processor.Name, err)
 		}
+		isTLS := u.Scheme == "https"
+		c.hosts = append(c.hosts, &fasthttp.HostClient{
+			Addr:                fasthttp.AddMissingPort(u.Host, isTLS),
+			IsTLS:               isTLS,
+			MaxConns:            2000,
+			MaxIdleConnDuration: 90 * time.Second,
+		})
+		c.paymentUrls = append(c.paymentUrls, processor.Url+"/payments")
+		c.Windows = append(c.Windows, NewWindow(statsWindow, 10))
+		c.Breakers = append(c.Breakers, NewBreaker(breaker))
+		c.Limiters = append(c.Limiters, NewLimiter(limiter))
 	}
-	return -1, ErrBothFailed
+	return c, nil
 }
 
-var ErrBothFailed = &ProcessorError{"Both endpoints failed"}
+var ErrNotFound = &ProcessorError{"Payment not found"}
 
 type ProcessorError struct {
 	Message string
@@ -86,51 +76,172 @@ func (e *ProcessorError) Error() string {
 	return e.Message
 }
 
-// Internal POST logic
-func (c *Client) PostJSON(url string, event model.PaymentEvent) bool {
-	body, err := easyjson.Marshal(event)
-	if err != nil {
-		log.Printf("JSON marshal error: %v", err)
-		return false
+// Outcome classifies a payment POST by what it tells us about the charge.
+type Outcome int
+
+const (
+	// OutcomeSuccess means the processor accepted the payment.
+	OutcomeSuccess Outcome = iota
+	// OutcomeRetryable means the processor did not take the payment and it
+	// can be sent again.
+	OutcomeRetryable
+	// OutcomeRejected means the processor refused the payment for good.
+	OutcomeRejected
+	// OutcomeUnknown means the request may or may not have been charged.
+	OutcomeUnknown
+	// OutcomeOpen means the processor's circuit breaker refused the request
+	// without sending it.
+	OutcomeOpen
+)
+
+// PostJSON sends a payment to processor and records the outcome in its
+// breaker and stats. It returns nil once the processor accepted it.
+func (c *Client) PostJSON(ctx context.Context, processor int, event model.PaymentEvent) error {
+	if !c.Breakers[processor].Allow() {
+		return ErrBreakerOpen
 	}
+	start := time.Now()
+	err := c.postJSON(ctx, processor, event)
+	outcome := Classify(err)
+	c.Breakers[processor].Record(outcome == OutcomeSuccess || outcome == OutcomeRejected)
+	c.Windows[processor].Record(time.Since(start), outcome)
+	if c.Prober != nil {
+		switch outcome {
+		case OutcomeSuccess:
+			c.Prober.Observe(processor, true)
+		case OutcomeRetryable:
+			c.Prober.Observe(processor, false)
+		}
+	}
+	return err
+}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/payments", url), bytes.NewBuffer(body))
-	if err != nil {
-		log.Printf("Request creation error: %v", err)
-		return false
+func (c *Client) postJSON(ctx context.Context, processor int, event model.PaymentEvent) error {
+	req := fasthttp.AcquireRequest()
+	var w jwriter.Writer
+	event.MarshalEasyJSON(&w)
+	if w.Error != nil {
+		fasthttp.ReleaseRequest(req)
+		return w.Error
+	}
+	req.SetRequestURI(c.paymentUrls[processor])
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	if _, err := w.DumpTo(req.BodyWriter()); err != nil {
+		fasthttp.ReleaseRequest(req)
+		return err
 	}
+	return c.do(ctx, c.hosts[processor], req, func(resp *fasthttp.Response) error {
+		if status := resp.StatusCode(); status < 200 || status >= 300 {
+			return &StatusError{Url: c.paymentUrls[processor], Status: status}
+		}
+		return nil
+	})
+}
 
-	req.Header.Set("Content-Type", "application/json")
+type doer interface {
+	DoDeadline(req *fasthttp.Request, resp *fasthttp.Response, deadline time.Time) error
+}
 
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return false
+// do sends req with doer and hands the response to read, then releases both.
+// The deadline is the earlier of ctx's and Timeout. A ctx with a deadline is
+// left to DoDeadline; when a ctx without one is cancelled first, do returns at
+// once and the request finishes in the background.
+func (c *Client) do(ctx context.Context, doer doer, req *fasthttp.Request, read func(resp *fasthttp.Response) error) error {
+	if err := ctx.Err(); err != nil {
+		fasthttp.ReleaseRequest(req)
+		return contextError(err)
+	}
+	deadline := time.Now().Add(c.Timeout)
+	d, hasDeadline := ctx.Deadline()
+	if hasDeadline && d.Before(deadline) {
+		deadline = d
+	}
+	run := func() error {
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+		if err := doer.DoDeadline(req, resp, deadline); err != nil {
+			return transportError(err)
+		}
+		return read(resp)
+	}
+	if hasDeadline || ctx.Done() == nil {
+		return run()
 	}
-	defer resp.Body.Close()
+	result := make(chan error, 1)
+	go func() {
+		result <- run()
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return contextError(ctx.Err())
+	}
+}
 
-	return resp.StatusCode >= 200 && resp.StatusCode < 300
+// get fetches url with doer and decodes a 200 response into dst.
+func (c *Client) get(ctx context.Context, doer doer, url string, dst easyjson.Unmarshaler) error {
+	req := fasthttp.AcquireRequest()
+	req.SetRequestURI(url)
+	return c.do(ctx, doer, req, func(resp *fasthttp.Response) error {
+		if status := resp.StatusCode(); status != fasthttp.StatusOK {
+			return &StatusError{Url: url, Status: status}
+		}
+		return easyjson.Unmarshal(resp.Body(), dst)
+	})
 }
 
-func (c *Client) ServiceHealth() (model.ServiceHealthResponse, error) {
-	resp, err := c.Client.Get(fmt.Sprintf("%s/health", c.HealthUrl))
-	if err != nil {
-		log.Printf("Service health error: %v", err)
-		return model.ServiceHealthResponse{}, err
+// Stats returns the observed traffic to processor over the rolling window.
+func (c *Client) Stats(processor int) Stats {
+	return c.Windows[processor].Stats()
+}
+
+func (c *Client) BreakerState(processor int) BreakerState {
+	return c.Breakers[processor].State()
+}
+
+func (c *Client) BreakerStatuses() model.BreakerStatuses {
+	urls := c.ProcessorUrls()
+	statuses := make(model.BreakerStatuses, len(urls))
+	for processor, url := range urls {
+		statuses[processor] = model.BreakerStatus{
+			Processor: c.Processors[processor].Name,
+			Url:       url,
+			State:     c.Breakers[processor].State().String(),
+			Failures:  c.Breakers[processor].Failures(),
+		}
 	}
-	defer resp.Body.Close()
+	return statuses
+}
 
-	if resp.StatusCode == 200 {
-		var serviceHealthResponse model.ServiceHealthResponse
-		err = easyjson.UnmarshalFromReader(resp.Body, &serviceHealthResponse)
-		if err != nil {
-			return model.ServiceHealthResponse{}, err
+func (c *Client) LimiterStatuses() model.LimiterStatuses {
+	statuses := make(model.LimiterStatuses, len(c.Limiters))
+	for processor, limiter := range c.Limiters {
+		limit, inFlight := limiter.Limit()
+		statuses[processor] = model.LimiterStatus{
+			Processor: c.Processors[processor].Name,
+			Limit:     limit,
+			InFlight:  inFlight,
 		}
-		return serviceHealthResponse, nil
 	}
-	return model.ServiceHealthResponse{}, errors.New("invalid service health response")
+	return statuses
+}
+
+func (c *Client) ServiceHealth(ctx context.Context) (model.ServiceHealthResponse, error) {
+	if c.Prober != nil {
+		return c.Prober.Health(), nil
+	}
+	var serviceHealthResponse model.ServiceHealthResponse
+	if err := c.get(ctx, c.Client, c.HealthUrl+"/health", &serviceHealthResponse); err != nil {
+		log.Printf("Service health error: %v", err)
+		return model.ServiceHealthResponse{}, err
+	}
+	return serviceHealthResponse, nil
 }
 
-func (c *Client) GetOtherSummary(otherUrl, from, to string) (model.SummaryResponse, error) {
+func (c *Client) GetOtherSummary(ctx context.Context, otherUrl, from, to string) (model.SummaryResponse, error) {
 	log.Println(otherUrl)
 	u, err := url.Parse(otherUrl + "/payments-summary")
 	if err != nil {
@@ -142,22 +253,115 @@ func (c *Client) GetOtherSummary(otherUrl, from, to string) (model.SummaryRespon
 	q.Set("single", "true")
 	u.RawQuery = q.Encode()
 
-	resp, err := c.Client.Get(u.String())
-	if err != nil {
+	var summary model.SummaryResponse
+	if err := c.get(ctx, c.Client, u.String(), &summary); err != nil {
 		log.Printf("Error: %v", err)
 		return model.SummaryResponse{}, err
 	}
-	defer resp.Body.Close()
+	return summary, nil
+}
 
-	if resp.StatusCode == 200 {
-		var summary model.SummaryResponse
-		err = easyjson.UnmarshalFromReader(resp.Body, &summary)
-		if err != nil {
-			return model.SummaryResponse{}, err
-		}
-		return summary, nil
+// ProcessorUrls returns the processor base URLs indexed by processor number.
+func (c *Client) ProcessorUrls() []string {
+	urls := make([]string, len(c.Processors))
+	for i, processor := range c.Processors {
+		urls[i] = processor.Url
+	}
+	return urls
+}
+
+// ProcessorNames returns the processor names indexed by processor number.
+func (c *Client) ProcessorNames() []string {
+	names := make([]string, len(c.Processors))
+	for i, processor := range c.Processors {
+		names[i] = processor.Name
+	}
+	return names
+}
+
+// Fees returns the fee rate charged by each processor, e.g. 0.05 for 5%.
+func (c *Client) Fees() []float64 {
+	fees := make([]float64, len(c.Processors))
+	for i, processor := range c.Processors {
+		fees[i] = processor.Fee
+	}
+	return fees
+}
+
+// LookupPayment asks processor whether it holds correlationID.
+func (c *Client) LookupPayment(ctx context.Context, processor int, correlationID string) (model.ProcessorPaymentResponse, error) {
+	var payment model.ProcessorPaymentResponse
+	err := c.get(ctx, c.hosts[processor], c.Processors[processor].Url+"/payments/"+correlationID, &payment)
+	if notFound(err) {
+		return model.ProcessorPaymentResponse{}, ErrNotFound
+	}
+	if err != nil {
+		return model.ProcessorPaymentResponse{}, err
+	}
+	return payment, nil
+}
+
+func (c *Client) GetOtherPayment(ctx context.Context, otherUrl, correlationID string) (model.PaymentStatusResponse, error) {
+	u, err := url.Parse(otherUrl + "/payments/" + url.PathEscape(correlationID))
+	if err != nil {
+		return model.PaymentStatusResponse{}, err
+	}
+	q := u.Query()
+	q.Set("single", "true")
+	u.RawQuery = q.Encode()
+
+	var status model.PaymentStatusResponse
+	err = c.get(ctx, c.Client, u.String(), &status)
+	if notFound(err) {
+		return model.PaymentStatusResponse{}, ErrNotFound
+	}
+	if err != nil {
+		return model.PaymentStatusResponse{}, err
 	}
+	return status, nil
+}
+
+// ForwardPayment posts a payment request body to the peer at otherUrl for it
+// to accept, and returns the peer's status and response body.
+func (c *Client) ForwardPayment(ctx context.Context, otherUrl string, body []byte) (int, []byte, error) {
+	return c.forward(ctx, fasthttp.MethodPost, otherUrl+"/payments?single=true", body)
+}
+
+// GetOtherDeadLetters lists the dead letters held by the peer at otherUrl.
+func (c *Client) GetOtherDeadLetters(ctx context.Context, otherUrl string) (model.DeadLetters, error) {
+	var letters model.DeadLetters
+	if err := c.get(ctx, c.Client, otherUrl+"/admin/dead-letters?single=true", &letters); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// ForwardDeadLetter sends an admin request for one dead letter, path
+// included, to the peer at otherUrl, and returns the peer's status and
+// response body.
+func (c *Client) ForwardDeadLetter(ctx context.Context, otherUrl, method, path string, body []byte) (int, []byte, error) {
+	return c.forward(ctx, method, otherUrl+path+"?single=true", body)
+}
 
-	return model.SummaryResponse{}, errors.New("can't get other summary")
+func (c *Client) forward(ctx context.Context, method, uri string, body []byte) (int, []byte, error) {
+	req := fasthttp.AcquireRequest()
+	req.SetRequestURI(uri)
+	req.Header.SetMethod(method)
+	if len(body) > 0 {
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+	}
+	var status int
+	var respBody []byte
+	err := c.do(ctx, c.Client, req, func(resp *fasthttp.Response) error {
+		status = resp.StatusCode()
+		respBody = append([]byte(nil), resp.Body()...)
+		return nil
+	})
+	return status, respBody, err
+}
 
+func notFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Status == fasthttp.StatusNotFound
 }
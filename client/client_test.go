@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rb2025-v3/model"
+	"testing"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+func newBenchmarkServer(b *testing.B) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	b.Cleanup(server.Close)
+	return server
+}
+
+var benchmarkEvent = model.PaymentEvent{
+	CorrelationID: "4a7901b8-7d26-4d0d-aa6e-3f7c6c8a1b2e",
+	Amount:        19900,
+	RequestedAt:   "2025-07-15T12:34:56.000Z",
+}
+
+// BenchmarkPostJSON compares the fasthttp path with the net/http one it
+// replaced. The test server's allocations are counted in both.
+func BenchmarkPostJSON(b *testing.B) {
+	b.Run("fasthttp", func(b *testing.B) {
+		server := newBenchmarkServer(b)
+		c, err := NewClient([]Processor{{Name: "default", Url: server.URL}}, "", time.Second,
+			BreakerSettings{FailureThreshold: 5, OpenTimeout: time.Second, HalfOpenProbes: 1},
+			LimiterSettings{Initial: 1})
+		if err != nil {
+			b.Fatal(err)
+		}
+		// Workers always send with a deadline.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := c.PostJSON(ctx, 0, benchmarkEvent); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("net/http", func(b *testing.B) {
+		server := newBenchmarkServer(b)
+		client := &http.Client{
+			Transport: &http.Transport{MaxIdleConns: 2000, MaxIdleConnsPerHost: 2000, IdleConnTimeout: 90 * time.Second},
+			Timeout:   5 * time.Second,
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// The request path as it was before the switch to fasthttp.
+			body, err := easyjson.Marshal(benchmarkEvent)
+			if err != nil {
+				b.Fatal(err)
+			}
+			req, err := http.NewRequest("POST", server.URL+"/payments", bytes.NewBuffer(body))
+			if err != nil {
+				b.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(req)
+			if err != nil {
+				b.Fatal(err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	// ErrTimeout means no response arrived before the deadline; the request
+	// may have been received.
+	ErrTimeout = errors.New("request timed out")
+	// ErrConnectionRefused means no connection could be made, so the
+	// request was never sent.
+	ErrConnectionRefused = errors.New("connection refused")
+	// ErrBreakerOpen means the processor's circuit breaker refused the
+	// request without sending it.
+	ErrBreakerOpen = errors.New("circuit breaker open")
+)
+
+// StatusError is a response with a status code other than the expected one.
+type StatusError struct {
+	Url    string
+	Status int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Url, e.Status)
+}
+
+// ClientError reports a 4xx status, which sending again will not change.
+func (e *StatusError) ClientError() bool {
+	return e.Status >= 400 && e.Status < 500
+}
+
+// transportError maps a fasthttp failure onto ErrTimeout or
+// ErrConnectionRefused, keeping the original error in the message.
+func transportError(err error) error {
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &opErr) && opErr.Op == "dial",
+		errors.Is(err, fasthttp.ErrDialTimeout),
+		errors.Is(err, fasthttp.ErrNoFreeConns):
+		return fmt.Errorf("%w: %v", ErrConnectionRefused, err)
+	case errors.Is(err, fasthttp.ErrTimeout):
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
+
+// contextError maps a context deadline onto ErrTimeout, so a per-attempt
+// deadline reads the same as the client's own timeout.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
+
+// Classify tells what an error from PostJSON says about the charge.
+// Timeouts, cancellations and other transport failures leave it unknown.
+func Classify(err error) Outcome {
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrBreakerOpen):
+		return OutcomeOpen
+	case errors.Is(err, ErrConnectionRefused):
+		return OutcomeRetryable
+	case errors.As(err, &statusErr):
+		if statusErr.ClientError() {
+			return OutcomeRejected
+		}
+		return OutcomeRetryable
+	}
+	return OutcomeUnknown
+}
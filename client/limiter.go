@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type LimiterSettings struct {
+	Initial int
+	Min     int
+	Max     int
+	// Tolerance is how many times the baseline latency a window of requests
+	// may take on average before the limit backs off.
+	Tolerance float64
+	// Backoff multiplies the limit when it backs off.
+	Backoff float64
+}
+
+// limiterMinWindow is the fewest requests the limit is judged on, so that a
+// small limit is not moved by a single slow request.
+const limiterMinWindow = 10
+
+// limiterMaxFailureRate is the share of failed requests in a window above
+// which the limit backs off.
+const limiterMaxFailureRate = 0.1
+
+// Limiter is an AIMD concurrency limit for one processor. It judges requests
+// in windows of one limit's worth: the limit grows by one after a good window
+// and shrinks by Backoff when more than limiterMaxFailureRate of the window
+// failed or its average latency exceeded Tolerance times the baseline, a
+// moving average of the windows' latency.
+type Limiter struct {
+	Settings LimiterSettings
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	baseline time.Duration
+	window   limiterWindow
+	waiters  []chan struct{}
+}
+
+type limiterWindow struct {
+	requests int
+	failures int
+	latency  time.Duration
+}
+
+func NewLimiter(settings LimiterSettings) *Limiter {
+	if settings.Min <= 0 {
+		settings.Min = 1
+	}
+	if settings.Max < settings.Min {
+		settings.Max = settings.Min
+	}
+	settings.Initial = min(max(settings.Initial, settings.Min), settings.Max)
+	if settings.Tolerance <= 1 {
+		settings.Tolerance = 2
+	}
+	if settings.Backoff <= 0 || settings.Backoff >= 1 {
+		settings.Backoff = 0.9
+	}
+	return &Limiter{Settings: settings, limit: float64(settings.Initial)}
+}
+
+// Acquire waits for a free slot, in arrival order, or until ctx is done.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if len(l.waiters) == 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, waiter := range l.waiters {
+		if waiter == ready {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return ctx.Err()
+		}
+	}
+	// Handed a slot while giving up; pass it on.
+	l.inFlight--
+	l.grantLocked()
+	return ctx.Err()
+}
+
+// Release frees a slot and adjusts the limit by how the request went.
+func (l *Limiter) Release(latency time.Duration, outcome Outcome) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.window.requests++
+	l.window.latency += latency
+	if outcome == OutcomeRetryable || outcome == OutcomeUnknown {
+		l.window.failures++
+	}
+	if l.window.requests >= max(int(l.limit), limiterMinWindow) {
+		l.adjustLocked()
+	}
+	l.grantLocked()
+}
+
+// adjustLocked moves the limit by how the window went and starts a new one.
+func (l *Limiter) adjustLocked() {
+	average := l.window.latency / time.Duration(l.window.requests)
+	failed := float64(l.window.failures) > limiterMaxFailureRate*float64(l.window.requests)
+	slow := l.baseline > 0 && float64(average) > l.Settings.Tolerance*float64(l.baseline)
+	if failed || slow {
+		l.limit = max(l.limit*l.Settings.Backoff, float64(l.Settings.Min))
+	} else {
+		l.limit = min(l.limit+1, float64(l.Settings.Max))
+	}
+	if l.baseline == 0 {
+		l.baseline = average
+	} else {
+		l.baseline += (average - l.baseline) / 4
+	}
+	l.window = limiterWindow{}
+}
+
+// Cancel frees a slot whose request was never sent, leaving the limit as it
+// is.
+func (l *Limiter) Cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.grantLocked()
+}
+
+func (l *Limiter) grantLocked() {
+	for len(l.waiters) > 0 && l.inFlight < int(l.limit) {
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+	}
+}
+
+// Limit returns the current limit and how many requests hold a slot.
+func (l *Limiter) Limit() (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit), l.inFlight
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func release(t *testing.T, l *Limiter, n int, latency func() time.Duration, outcome Outcome) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := l.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		l.Release(latency(), outcome)
+	}
+}
+
+func TestLimiterSteadyUnderJitter(t *testing.T) {
+	settings := LimiterSettings{Initial: 20, Min: 1, Max: 200, Tolerance: 2, Backoff: 0.9}
+	l := NewLimiter(settings)
+	rng := rand.New(rand.NewSource(1))
+	jitter := func() time.Duration { return time.Duration(rng.Int63n(int64(20 * time.Millisecond))) }
+	release(t, l, 2000, jitter, OutcomeSuccess)
+	if limit, _ := l.Limit(); limit < settings.Initial {
+		t.Fatalf("limit fell to %d under 0-20ms jitter, want at least %d", limit, settings.Initial)
+	}
+}
+
+func TestLimiterBacksOffOnErrors(t *testing.T) {
+	l := NewLimiter(LimiterSettings{Initial: 20, Min: 1, Max: 200, Tolerance: 2, Backoff: 0.5})
+	fast := func() time.Duration { return 5 * time.Millisecond }
+	release(t, l, 200, fast, OutcomeRetryable)
+	if limit, _ := l.Limit(); limit != 1 {
+		t.Fatalf("limit = %d after failures, want 1", limit)
+	}
+}
+
+func TestLimiterBacksOffWhenSlow(t *testing.T) {
+	l := NewLimiter(LimiterSettings{Initial: 20, Min: 1, Max: 200, Tolerance: 2, Backoff: 0.5})
+	release(t, l, 200, func() time.Duration { return 5 * time.Millisecond }, OutcomeSuccess)
+	before, _ := l.Limit()
+	release(t, l, 2*before, func() time.Duration { return 50 * time.Millisecond }, OutcomeSuccess)
+	if after, _ := l.Limit(); after >= before {
+		t.Fatalf("limit = %d after a slow window, want below %d", after, before)
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"log"
+	"rb2025-v3/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MinProbeInterval is the shortest poll interval the processors' rate limit
+// on their service-health endpoints allows.
+const MinProbeInterval = 5 * time.Second
+
+// Prober polls the processors' service-health endpoints in-process. The
+// processors allow one call every five seconds, so Interval is never shorter
+// than MinProbeInterval; instances without a prober fetch its results from
+// the instance that runs it through HEALTH_URL.
+type Prober struct {
+	Client   *Client
+	Interval time.Duration
+	// Threshold is how many live payments in a row must disagree with the
+	// last poll before Observe overrides it.
+	Threshold int
+	mu        sync.RWMutex
+	health    []model.ProcessorHealthResponse
+	streaks   []atomic.Int32
+	nextPoll  time.Time
+}
+
+func NewProber(c *Client, interval time.Duration, threshold int) *Prober {
+	if interval < MinProbeInterval {
+		interval = MinProbeInterval
+	}
+	if threshold <= 0 {
+		threshold = 1
+	}
+	// Assume every processor is up until the first poll says otherwise.
+	return &Prober{
+		Client:    c,
+		Interval:  interval,
+		Threshold: threshold,
+		health:    make([]model.ProcessorHealthResponse, len(c.Processors)),
+		streaks:   make([]atomic.Int32, len(c.Processors)),
+		nextPoll:  time.Now(),
+	}
+}
+
+func (p *Prober) Start() {
+	go func() {
+		for {
+			p.poll()
+			time.Sleep(p.Interval)
+		}
+	}()
+}
+
+func (p *Prober) poll() {
+	urls := p.Client.ProcessorUrls()
+	var wg sync.WaitGroup
+	results := make([]*model.ProcessorHealthResponse, len(urls))
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			health, err := p.Client.ProcessorHealth(context.Background(), i)
+			if err != nil {
+				log.Printf("Processor health error for %s: %v", url, err)
+				return
+			}
+			results[i] = &health
+		}()
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, health := range results {
+		// A failed or rate-limited poll keeps the last known value.
+		if health != nil {
+			p.health[i] = *health
+			p.streaks[i].Store(0)
+		}
+	}
+	p.nextPoll = time.Now().Add(p.Interval)
+}
+
+// Observe folds the outcome of a live payment into the last poll. Once
+// Threshold payments in a row disagree with it, the processor is reported
+// the way they went until the next poll.
+func (p *Prober) Observe(processor int, healthy bool) {
+	if processor < 0 || processor >= len(p.health) {
+		return
+	}
+	p.mu.RLock()
+	failing := p.health[processor].Failing
+	p.mu.RUnlock()
+	streak := &p.streaks[processor]
+	if failing == !healthy {
+		if streak.Load() != 0 {
+			streak.Store(0)
+		}
+		return
+	}
+	if streak.Add(1) < int32(p.Threshold) {
+		return
+	}
+	p.mu.Lock()
+	p.health[processor].Failing = !healthy
+	p.mu.Unlock()
+	streak.Store(0)
+}
+
+// Health returns the latest results for every processor, plus the
+// default/fallback fields served by the external service-health container.
+func (p *Prober) Health() model.ServiceHealthResponse {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	nextCheck := int(time.Until(p.nextPoll).Milliseconds())
+	if nextCheck < 0 {
+		nextCheck = 0
+	}
+	response := model.ServiceHealthResponse{
+		NextCheck:  nextCheck,
+		Processors: make([]model.ProcessorHealth, len(p.health)),
+	}
+	for i, health := range p.health {
+		name := p.Client.Processors[i].Name
+		response.Processors[i] = model.ProcessorHealth{
+			Name:        name,
+			Healthy:     !health.Failing,
+			MinResponse: health.MinResponseTime,
+		}
+		switch name {
+		case "default":
+			response.DefaultHealth = !health.Failing
+			response.DefaultMinResponse = health.MinResponseTime
+		case "fallback":
+			response.FallbackHealth = !health.Failing
+			response.FallbackMinResponse = health.MinResponseTime
+		}
+	}
+	return response
+}
+
+func (c *Client) ProcessorHealth(ctx context.Context, processor int) (model.ProcessorHealthResponse, error) {
+	var health model.ProcessorHealthResponse
+	if err := c.get(ctx, c.hosts[processor], c.Processors[processor].Url+"/payments/service-health", &health); err != nil {
+		return model.ProcessorHealthResponse{}, err
+	}
+	return health, nil
+}
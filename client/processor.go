@@ -0,0 +1,54 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Processor is one configured payment processor. Its position in the
+// configured list is the processor number stored with each payment, so
+// processors must only ever be appended to the list.
+type Processor struct {
+	Name     string
+	Url      string
+	Fee      float64
+	Priority int
+}
+
+// ParseProcessors parses a comma-separated list of name|url|fee|priority
+// entries, e.g. "default|http://pp-default:8080|0.05|0". Fee and priority
+// are optional.
+func ParseProcessors(spec string) ([]Processor, error) {
+	var processors []Processor
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.Split(entry, "|")
+		if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("invalid processor %q: want name|url|fee|priority", entry)
+		}
+		processor := Processor{Name: fields[0], Url: fields[1]}
+		if len(fields) > 2 && fields[2] != "" {
+			fee, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fee for processor %s: %w", processor.Name, err)
+			}
+			processor.Fee = fee
+		}
+		if len(fields) > 3 && fields[3] != "" {
+			priority, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority for processor %s: %w", processor.Name, err)
+			}
+			processor.Priority = priority
+		}
+		processors = append(processors, processor)
+	}
+	if len(processors) == 0 {
+		return nil, fmt.Errorf("no processors configured")
+	}
+	return processors, nil
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets.
+var latencyBounds = [...]time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+type statsBucket struct {
+	start     time.Time
+	requests  int
+	errors    int
+	timeouts  int
+	latency   time.Duration
+	histogram [len(latencyBounds) + 1]int
+}
+
+// Stats summarises the live traffic to one processor over a Window.
+type Stats struct {
+	Requests    int
+	Errors      int
+	Timeouts    int
+	MeanLatency time.Duration
+	histogram   [len(latencyBounds) + 1]int
+}
+
+func (s Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors+s.Timeouts) / float64(s.Requests)
+}
+
+// Percentile returns the upper bound of the histogram bucket holding the q-th
+// quantile latency, or 0 without samples.
+func (s Stats) Percentile(q float64) time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	rank := int(q * float64(s.Requests))
+	seen := 0
+	for i, n := range s.histogram {
+		seen += n
+		if seen > rank {
+			if i < len(latencyBounds) {
+				return latencyBounds[i]
+			}
+			break
+		}
+	}
+	return latencyBounds[len(latencyBounds)-1]
+}
+
+// Window is a rolling window of payment outcomes, split into Buckets slices
+// of equal width so old observations expire in steps.
+type Window struct {
+	Span    time.Duration
+	mu      sync.Mutex
+	buckets []statsBucket
+}
+
+// minBucketWidth keeps a Window's buckets at least a millisecond wide, so a
+// zero or tiny span still yields a usable window.
+const minBucketWidth = time.Millisecond
+
+func NewWindow(span time.Duration, buckets int) *Window {
+	if buckets <= 0 {
+		buckets = 1
+	}
+	if span < time.Duration(buckets)*minBucketWidth {
+		span = time.Duration(buckets) * minBucketWidth
+	}
+	return &Window{Span: span, buckets: make([]statsBucket, buckets)}
+}
+
+func (w *Window) width() time.Duration {
+	return w.Span / time.Duration(len(w.buckets))
+}
+
+func (w *Window) Record(latency time.Duration, outcome Outcome) {
+	now := time.Now()
+	start := now.Truncate(w.width())
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[int(start.UnixNano()/int64(w.width()))%len(w.buckets)]
+	if !b.start.Equal(start) {
+		*b = statsBucket{start: start}
+	}
+	b.requests++
+	switch outcome {
+	case OutcomeRetryable:
+		b.errors++
+	case OutcomeUnknown:
+		b.timeouts++
+	}
+	b.latency += latency
+	i := 0
+	for i < len(latencyBounds) && latency > latencyBounds[i] {
+		i++
+	}
+	b.histogram[i]++
+}
+
+func (w *Window) Stats() Stats {
+	cutoff := time.Now().Add(-w.Span)
+	var stats Stats
+	var latency time.Duration
+	w.mu.Lock()
+	for _, b := range w.buckets {
+		if b.start.Before(cutoff) {
+			continue
+		}
+		stats.Requests += b.requests
+		stats.Errors += b.errors
+		stats.Timeouts += b.timeouts
+		latency += b.latency
+		for i, n := range b.histogram {
+			stats.histogram[i] += n
+		}
+	}
+	w.mu.Unlock()
+	if stats.Requests > 0 {
+		stats.MeanLatency = latency / time.Duration(stats.Requests)
+	}
+	return stats
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWindowClampsSpan(t *testing.T) {
+	for _, span := range []time.Duration{-time.Second, 0, 5 * time.Nanosecond} {
+		w := NewWindow(span, 10)
+		w.Record(time.Millisecond, OutcomeSuccess)
+		if stats := w.Stats(); stats.Requests != 1 {
+			t.Fatalf("NewWindow(%v, 10) counted %d requests, want 1", span, stats.Requests)
+		}
+	}
+}
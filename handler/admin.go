@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"log"
+	"rb2025-v3/model"
+	"sort"
+	"strings"
+
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+// AdminDeadLetters serves /admin/dead-letters:
+//
+//	GET  /admin/dead-letters              list dead letters
+//	GET  /admin/dead-letters/{id}         inspect one
+//	POST /admin/dead-letters/{id}/replay  resubmit, optionally with a corrected body
+//	POST /admin/dead-letters/{id}/discard drop it for good
+//
+// Each node keeps its own dead letters, so unless single is set the list
+// includes the peer's, and a letter this node does not hold is handled by
+// the peer.
+func (h *Handler) AdminDeadLetters(ctx *fasthttp.RequestCtx) {
+	rest := strings.Trim(strings.TrimPrefix(string(ctx.Path()), "/admin/dead-letters"), "/")
+	single := string(ctx.QueryArgs().Peek("single"))
+	if rest == "" {
+		if !ctx.IsGet() {
+			writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		letters := h.DeadLetters.List()
+		if single == "" && h.OtherUrl != "" {
+			otherLetters, err := h.Client.GetOtherDeadLetters(ctx, h.OtherUrl)
+			if err != nil {
+				log.Printf("Error getting other dead letters: %v", err)
+			}
+			letters = append(letters, otherLetters...)
+			sort.SliceStable(letters, func(i, j int) bool { return letters[i].DeadAt < letters[j].DeadAt })
+		}
+		ctx.Response.Header.Set("Content-Type", "application/json")
+		if _, err := easyjson.MarshalToWriter(letters, ctx); err != nil {
+			writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+		}
+		return
+	}
+
+	correlationID, action, _ := strings.Cut(rest, "/")
+	letter, ok := h.DeadLetters.Get(correlationID)
+	if !ok && single == "" && h.OtherUrl != "" {
+		status, body, err := h.Client.ForwardDeadLetter(ctx, h.OtherUrl, string(ctx.Method()), string(ctx.Path()), ctx.PostBody())
+		if err != nil {
+			log.Printf("Error forwarding dead letter request: %v", err)
+		}
+		relay(ctx, status, body, err)
+		return
+	}
+	if !ok {
+		writeError(ctx, fasthttp.StatusNotFound, "Not Found")
+		return
+	}
+	switch action {
+	case "":
+		if !ctx.IsGet() {
+			writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		ctx.Response.Header.Set("Content-Type", "application/json")
+		if _, err := easyjson.MarshalToWriter(&letter, ctx); err != nil {
+			writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+		}
+	case "replay":
+		if !ctx.IsPost() {
+			writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		h.replayDeadLetter(ctx, letter)
+	case "discard":
+		if !ctx.IsPost() {
+			writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		h.DeadLetters.Remove(correlationID)
+		h.Repository.Forget(correlationID)
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+	default:
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	}
+}
+
+func (h *Handler) replayDeadLetter(ctx *fasthttp.RequestCtx, letter model.DeadLetter) {
+	req := model.PaymentRequest{CorrelationID: letter.CorrelationID, Amount: letter.Amount, Currency: letter.Currency}
+	if body := ctx.PostBody(); len(body) > 0 {
+		fixed, verr := h.Validator.Decode(body)
+		if verr != nil {
+			writeValidationError(ctx, verr)
+			return
+		}
+		req.Amount = fixed.Amount
+		if fixed.Currency != "" {
+			req.Currency = fixed.Currency
+		}
+		if verr := h.Validator.Check(req); verr != nil {
+			writeValidationError(ctx, verr)
+			return
+		}
+	}
+	if err := h.Repository.Revive(req); err != nil {
+		writeError(ctx, fasthttp.StatusConflict, err.Error())
+		return
+	}
+	if !h.Queue.Push(req) {
+		h.Repository.Transition(req.CorrelationID, model.StateDead)
+		writeError(ctx, fasthttp.StatusTooManyRequests, "payment queue full")
+		return
+	}
+	h.DeadLetters.Remove(req.CorrelationID)
+	ctx.SetStatusCode(fasthttp.StatusAccepted)
+}
+
+func (h *Handler) AdminBreakers(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	statuses := h.Client.BreakerStatuses()
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(statuses, ctx); err != nil {
+		writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+	}
+}
+
+func (h *Handler) AdminLimits(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	statuses := h.Client.LimiterStatuses()
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(statuses, ctx); err != nil {
+		writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+	}
+}
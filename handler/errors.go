@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"rb2025-v3/model"
+
+	"github.com/mailru/easyjson"
+	"github.com/valyala/fasthttp"
+)
+
+// writeError answers with status and a model.ErrorResponse body.
+func writeError(ctx *fasthttp.RequestCtx, status int, message string, fields ...model.FieldError) {
+	writeErrorResponse(ctx, status, model.ErrorResponse{Error: message, Fields: fields})
+}
+
+func writeErrorResponse(ctx *fasthttp.RequestCtx, status int, response model.ErrorResponse) {
+	body, err := easyjson.Marshal(response)
+	if err != nil {
+		ctx.Error(response.Error, status)
+		return
+	}
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(body)
+}
+
+func writeValidationError(ctx *fasthttp.RequestCtx, err *ValidationError) {
+	writeError(ctx, err.Status, err.Message, err.Fields...)
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"hash/fnv"
 	"log"
 	"rb2025-v3/client"
 	"rb2025-v3/model"
+	"rb2025-v3/queue"
 	"rb2025-v3/repository"
+	"strings"
 	"time"
 
 	"github.com/mailru/easyjson"
@@ -12,41 +15,141 @@ import (
 )
 
 type Handler struct {
-	Jobs       chan<- model.PaymentRequest
-	Repository *repository.Repository
-	Client     *client.Client
-	OtherUrl   string
+	Queue       *queue.Queue
+	Repository  *repository.Repository
+	DeadLetters *repository.DeadLetterStore
+	Client      *client.Client
+	OtherUrl    string
+	// Shard is this node's half of the correlationId space, 0 or 1. With a
+	// peer, payments in the other half are forwarded to it, so every
+	// correlationId is deduplicated on a single node.
+	Shard     int
+	Validator Validator
 }
 
-func NewHandler(jobs chan<- model.PaymentRequest, r *repository.Repository, c *client.Client, otherUrl string) *Handler {
-	return &Handler{Jobs: jobs, Repository: r, Client: c, OtherUrl: otherUrl}
+func NewHandler(q *queue.Queue, r *repository.Repository, dl *repository.DeadLetterStore, c *client.Client, otherUrl string) *Handler {
+	return &Handler{Queue: q, Repository: r, DeadLetters: dl, Client: c, OtherUrl: otherUrl, Validator: Validator{CorrelationIDFormat: CorrelationIDUUID}}
 }
 
 func (h *Handler) PostPayments(ctx *fasthttp.RequestCtx) {
 	if !ctx.IsPost() {
-		ctx.Error("Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
 		return
 	}
 
-	var req model.PaymentRequest
-	err := easyjson.Unmarshal(ctx.PostBody(), &req)
-	if err != nil {
-		ctx.Error("Bad Request", fasthttp.StatusBadRequest)
+	req, verr := h.Validator.Decode(ctx.PostBody())
+	if verr == nil {
+		verr = h.Validator.Check(req)
+	}
+	if verr != nil {
+		writeValidationError(ctx, verr)
+		return
+	}
+
+	if !h.ownsPayment(req.CorrelationID) && len(ctx.QueryArgs().Peek("single")) == 0 {
+		h.forwardPayment(ctx)
+		return
+	}
+
+	reserved, state := h.Repository.Reserve(req)
+	if !reserved {
+		if state == model.StateConfirmed {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		} else {
+			writeErrorResponse(ctx, fasthttp.StatusConflict, model.ErrorResponse{Error: "payment already submitted", State: state})
+		}
 		return
 	}
 
-	select {
-	case h.Jobs <- req:
+	if h.Queue.Push(req) {
 		ctx.SetStatusCode(fasthttp.StatusCreated)
-	default:
-		ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+	} else {
+		h.Repository.Release(req.CorrelationID)
+		writeError(ctx, fasthttp.StatusTooManyRequests, "payment queue full")
+	}
+
+}
+
+// ownsPayment reports whether correlationID is deduplicated on this node.
+func (h *Handler) ownsPayment(correlationID string) bool {
+	if h.OtherUrl == "" {
+		return true
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(correlationID))
+	return int(hash.Sum32()%2) == h.Shard
+}
+
+// forwardPayment hands the request to the peer that owns its correlationId
+// and relays the answer. Without the peer the payment is refused rather than
+// accepted here, where a duplicate could not be detected.
+func (h *Handler) forwardPayment(ctx *fasthttp.RequestCtx) {
+	status, body, err := h.Client.ForwardPayment(ctx, h.OtherUrl, ctx.PostBody())
+	if err != nil {
+		log.Printf("Error forwarding payment: %v", err)
+	}
+	relay(ctx, status, body, err)
+}
+
+// relay answers with the peer's response to a forwarded request.
+func relay(ctx *fasthttp.RequestCtx, status int, body []byte, err error) {
+	if err != nil {
+		writeError(ctx, fasthttp.StatusServiceUnavailable, "peer unavailable")
+		return
+	}
+	ctx.SetStatusCode(status)
+	if len(body) > 0 {
+		ctx.SetContentType("application/json")
+		ctx.SetBody(body)
 	}
+}
+
+func (h *Handler) GetPayment(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	correlationID := strings.TrimPrefix(string(ctx.Path()), "/payments/")
+	single := string(ctx.QueryArgs().Peek("single"))
+	status, ok := h.Repository.Lookup(correlationID)
+	if !ok && single == "" && h.OtherUrl != "" {
+		otherStatus, err := h.Client.GetOtherPayment(ctx, h.OtherUrl, correlationID)
+		if err != nil && err != client.ErrNotFound {
+			log.Printf("Error getting other payment: %v", err)
+		}
+		status, ok = otherStatus, err == nil
+	}
+	if !ok {
+		writeError(ctx, fasthttp.StatusNotFound, "Not Found")
+		return
+	}
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(&status, ctx); err != nil {
+		writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+	}
+}
 
+// ServiceHealth shares this instance's processor health with peers that
+// point HEALTH_URL at it.
+func (h *Handler) ServiceHealth(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	health, err := h.Client.ServiceHealth(ctx)
+	if err != nil {
+		writeError(ctx, fasthttp.StatusServiceUnavailable, "Service Unavailable")
+		return
+	}
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	if _, err := easyjson.MarshalToWriter(&health, ctx); err != nil {
+		writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
+	}
 }
 
 func (h *Handler) PurgePayments(ctx *fasthttp.RequestCtx) {
 	if !ctx.IsPost() {
-		ctx.Error("Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
 		return
 	}
 	h.Repository.PurgePayments()
@@ -55,7 +158,7 @@ func (h *Handler) PurgePayments(ctx *fasthttp.RequestCtx) {
 
 func (h *Handler) GetSummary(ctx *fasthttp.RequestCtx) {
 	if !ctx.IsGet() {
-		ctx.Error("Method Not Allowed", fasthttp.StatusMethodNotAllowed)
+		writeError(ctx, fasthttp.StatusMethodNotAllowed, "Method Not Allowed")
 		return
 	}
 	fromStr := string(ctx.QueryArgs().Peek("from"))
@@ -71,18 +174,52 @@ func (h *Handler) GetSummary(ctx *fasthttp.RequestCtx) {
 	}
 	summary := h.Repository.GetSummary(from, to)
 	if single == "" && h.OtherUrl != "" {
-		otherSummary, err := h.Client.GetOtherSummary(h.OtherUrl, fromStr, toStr)
+		otherSummary, err := h.Client.GetOtherSummary(ctx, h.OtherUrl, fromStr, toStr)
 		if err != nil {
 			log.Printf("Error getting other summary: %v", err)
 		} else {
-			summary.Default.TotalAmount += otherSummary.Default.TotalAmount
-			summary.Default.TotalRequests += otherSummary.Default.TotalRequests
-			summary.Fallback.TotalAmount += otherSummary.Fallback.TotalAmount
-			summary.Fallback.TotalRequests += otherSummary.Fallback.TotalRequests
+			if otherSummary.Processors == nil {
+				// Peers that predate per-processor summaries.
+				otherSummary.Processors = map[string]model.Summary{
+					"default":  otherSummary.Default,
+					"fallback": otherSummary.Fallback,
+				}
+			}
+			if otherSummary.Currencies == nil {
+				// Peers that predate per-currency summaries.
+				otherSummary.Currencies = make(map[string]map[model.Currency]model.Summary, len(otherSummary.Processors))
+				for name, other := range otherSummary.Processors {
+					otherSummary.Currencies[name] = map[model.Currency]model.Summary{model.DefaultCurrency: other}
+				}
+			}
+			for name, currencies := range otherSummary.Currencies {
+				if summary.Currencies[name] == nil {
+					summary.Currencies[name] = make(map[model.Currency]model.Summary, len(currencies))
+				}
+				for currency, other := range currencies {
+					merged := summary.Currencies[name][currency]
+					merged.TotalAmount += other.TotalAmount
+					merged.TotalRequests += other.TotalRequests
+					summary.Currencies[name][currency] = merged
+				}
+			}
+		}
+	}
+	fees := make(map[string]float64, len(h.Client.Processors))
+	for _, processor := range h.Client.Processors {
+		fees[processor.Name] = processor.Fee
+	}
+	for name, currencies := range summary.Currencies {
+		for currency, merged := range currencies {
+			merged.EstimatedFees = currency.Round(merged.TotalAmount.Mul(fees[name]))
+			currencies[currency] = merged
 		}
+		summary.Processors[name] = currencies[model.DefaultCurrency]
 	}
+	summary.Default = summary.Processors["default"]
+	summary.Fallback = summary.Processors["fallback"]
 	ctx.Response.Header.Set("Content-Type", "application/json")
 	if _, err := easyjson.MarshalToWriter(&summary, ctx); err != nil {
-		ctx.Error("Internal Server Error", fasthttp.StatusInternalServerError)
+		writeError(ctx, fasthttp.StatusInternalServerError, "Internal Server Error")
 	}
 }
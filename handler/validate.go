@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"rb2025-v3/model"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// CorrelationIDUUID accepts only canonical UUIDs.
+	CorrelationIDUUID = "uuid"
+	// CorrelationIDAny accepts any non-empty correlationId.
+	CorrelationIDAny = "any"
+)
+
+const maxCorrelationIDLength = 128
+
+var paymentFields = []string{"correlationId", "amount", "currency"}
+
+// Validator checks payment requests before they are accepted.
+type Validator struct {
+	// MaxAmount is the largest amount accepted; zero means no limit.
+	MaxAmount model.Money
+	// CorrelationIDFormat is CorrelationIDUUID or CorrelationIDAny.
+	CorrelationIDFormat string
+}
+
+// ValidationError says why a request was refused and with which status:
+// 400 for bodies that are not a JSON object, 422 for invalid fields.
+type ValidationError struct {
+	Status  int
+	Message string
+	Fields  []model.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(fields ...model.FieldError) *ValidationError {
+	return &ValidationError{Status: fasthttp.StatusUnprocessableEntity, Message: "invalid payment", Fields: fields}
+}
+
+// Decode parses a payment request body, refusing unknown fields and amounts
+// that cannot be represented exactly. It does not check the values; see
+// Check.
+func (v Validator) Decode(body []byte) (model.PaymentRequest, *ValidationError) {
+	var req model.PaymentRequest
+	unknown, err := unknownFields(body, paymentFields)
+	if err != nil {
+		return req, &ValidationError{Status: fasthttp.StatusBadRequest, Message: "malformed JSON"}
+	}
+	if len(unknown) > 0 {
+		fields := make([]model.FieldError, len(unknown))
+		for i, name := range unknown {
+			fields[i] = model.FieldError{Field: name, Reason: "unknown field"}
+		}
+		return req, invalid(fields...)
+	}
+	if err := easyjson.Unmarshal(body, &req); err != nil {
+		if errors.Is(err, model.ErrAmountNotNumber) {
+			return req, invalid(model.FieldError{Field: "amount", Reason: "must be a number"})
+		}
+		if errors.Is(err, model.ErrInvalidAmount) {
+			return req, invalid(model.FieldError{Field: "amount", Reason: err.Error()})
+		}
+		return req, &ValidationError{Status: fasthttp.StatusBadRequest, Message: "malformed JSON"}
+	}
+	if req.Currency != "" {
+		req.Currency = req.Currency.OrDefault()
+	}
+	return req, nil
+}
+
+// Check validates the values of a decoded payment request.
+func (v Validator) Check(req model.PaymentRequest) *ValidationError {
+	var fields []model.FieldError
+	switch {
+	case req.CorrelationID == "":
+		fields = append(fields, model.FieldError{Field: "correlationId", Reason: "required"})
+	case len(req.CorrelationID) > maxCorrelationIDLength:
+		fields = append(fields, model.FieldError{Field: "correlationId", Reason: fmt.Sprintf("longer than %d characters", maxCorrelationIDLength)})
+	case v.CorrelationIDFormat != CorrelationIDAny && !isUUID(req.CorrelationID):
+		fields = append(fields, model.FieldError{Field: "correlationId", Reason: "must be a UUID"})
+	}
+	switch {
+	case req.Amount <= 0:
+		fields = append(fields, model.FieldError{Field: "amount", Reason: "must be greater than zero"})
+	case v.MaxAmount > 0 && req.Amount > v.MaxAmount:
+		fields = append(fields, model.FieldError{Field: "amount", Reason: fmt.Sprintf("must not exceed %s", v.MaxAmount)})
+	}
+	if err := req.Currency.Check(req.Amount); err != nil {
+		field := "amount"
+		if errors.Is(err, model.ErrUnknownCurrency) {
+			field = "currency"
+		}
+		fields = append(fields, model.FieldError{Field: field, Reason: err.Error()})
+	}
+	if len(fields) > 0 {
+		return invalid(fields...)
+	}
+	return nil
+}
+
+// unknownFields returns the top-level keys of the JSON object in body that
+// are not in known.
+func unknownFields(body []byte, known []string) ([]string, error) {
+	l := jlexer.Lexer{Data: body}
+	var unknown []string
+	l.Delim('{')
+	for l.Ok() && !l.IsDelim('}') {
+		key := l.UnsafeString()
+		l.WantColon()
+		if !contains(known, key) {
+			unknown = append(unknown, key)
+		}
+		l.SkipRecursive()
+		l.WantComma()
+	}
+	l.Delim('}')
+	l.Consumed()
+	return unknown, l.Error()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isUUID reports whether s is a UUID in the canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"rb2025-v3/client"
 	"rb2025-v3/handler"
 	"rb2025-v3/model"
+	"rb2025-v3/queue"
 	"rb2025-v3/repository"
+	"rb2025-v3/wal"
 	"rb2025-v3/worker"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -32,19 +37,168 @@ func main() {
 
 	defaultUrl := readEnv("DEFAULT_URL", "http://localhost:8001")
 	fallbackUrl := readEnv("FALLBACK_URL", "http://localhost:8002")
-	healthUrl := readEnv("HEALTH_URL", "http://localhost:9001")
+	processorsSpec := readEnv("PROCESSORS", "")
+	healthUrl := readEnv("HEALTH_URL", "")
+	healthInterval, _ := strconv.Atoi(readEnv("HEALTH_INTERVAL", "5000"))
+	healthThreshold, _ := strconv.Atoi(readEnv("HEALTH_THRESHOLD", "5"))
+	passiveWindow, _ := strconv.Atoi(readEnv("PASSIVE_WINDOW", "1000"))
+	passiveMinSamples, _ := strconv.Atoi(readEnv("PASSIVE_MIN_SAMPLES", "10"))
+	passiveMaxErrorRate, _ := strconv.ParseFloat(readEnv("PASSIVE_MAX_ERROR_RATE", "0.5"), 64)
+	breakerFailureThreshold, _ := strconv.Atoi(readEnv("BREAKER_FAILURE_THRESHOLD", "5"))
+	breakerOpenTimeout, _ := strconv.Atoi(readEnv("BREAKER_OPEN_TIMEOUT", "1000"))
+	breakerHalfOpenProbes, _ := strconv.Atoi(readEnv("BREAKER_HALF_OPEN_PROBES", "1"))
 	otherUrl := readEnv("OTHER_URL", "")
+	intakeShard, _ := strconv.Atoi(readEnv("INTAKE_SHARD", "0"))
 	numWorkers, _ := strconv.Atoi(readEnv("NUM_WORKERS", "2000"))
-	defaultTolerance, _ := strconv.Atoi(readEnv("DEFAULT_TOLERANCE", "1500"))
-	semaphoreSize, _ := strconv.Atoi(readEnv("SEMAPHORE_SIZE", "50"))
+	defaultTolerance, _ := strconv.Atoi(readEnv("DEFAULT_TOLERANCE", "1000"))
+	defaultFee, _ := strconv.ParseFloat(readEnv("DEFAULT_FEE", "0.05"), 64)
+	fallbackFee, _ := strconv.ParseFloat(readEnv("FALLBACK_FEE", "0.15"), 64)
+	latencyPenalty, _ := strconv.ParseFloat(readEnv("ROUTER_LATENCY_PENALTY", "0.1"), 64)
+	routerName := readEnv("ROUTER", "cost-aware")
+	routerWeights := readEnv("ROUTER_WEIGHTS", "")
+	limitInitial, _ := strconv.Atoi(readEnv("LIMIT_INITIAL", "20"))
+	limitMin, _ := strconv.Atoi(readEnv("LIMIT_MIN", "1"))
+	limitMax, _ := strconv.Atoi(readEnv("LIMIT_MAX", "200"))
+	limitTolerance, _ := strconv.ParseFloat(readEnv("LIMIT_TOLERANCE", "2"), 64)
+	limitBackoff, _ := strconv.ParseFloat(readEnv("LIMIT_BACKOFF", "0.9"), 64)
 	jobsBufferSize, _ := strconv.Atoi(readEnv("JOBS_BUFFER_SIZE", "10000"))
-	workerSleep, _ := strconv.Atoi(readEnv("WORKER_SLEEP", "50"))
+	walDir := readEnv("WAL_DIR", "")
+	walSegmentSize, _ := strconv.ParseInt(readEnv("WAL_SEGMENT_SIZE", "16777216"), 10, 64)
+	walSyncInterval, _ := strconv.Atoi(readEnv("WAL_SYNC_INTERVAL", "10"))
+	walMaxSegments, _ := strconv.Atoi(readEnv("WAL_MAX_SEGMENTS", "4"))
+	dedupeRetention, _ := strconv.Atoi(readEnv("DEDUPE_RETENTION", "600000"))
+	logTransitions, _ := strconv.ParseBool(readEnv("LOG_TRANSITIONS", "false"))
+	reconcileInterval, _ := strconv.Atoi(readEnv("RECONCILE_INTERVAL", "1000"))
+	retryInitialDelay, _ := strconv.Atoi(readEnv("RETRY_INITIAL_DELAY", "100"))
+	retryMultiplier, _ := strconv.ParseFloat(readEnv("RETRY_MULTIPLIER", "2"), 64)
+	retryJitter, _ := strconv.ParseFloat(readEnv("RETRY_JITTER", "0.2"), 64)
+	retryMaxAttempts, _ := strconv.Atoi(readEnv("RETRY_MAX_ATTEMPTS", "10"))
+	retryMaxAge, _ := strconv.Atoi(readEnv("RETRY_MAX_AGE", "60000"))
+	retryQueueSize, _ := strconv.Atoi(readEnv("RETRY_QUEUE_SIZE", "10000"))
+	hedgePercentile, _ := strconv.ParseFloat(readEnv("HEDGE_PERCENTILE", "0"), 64)
+	hedgeMinDelay, _ := strconv.Atoi(readEnv("HEDGE_MIN_DELAY", "100"))
+	hedgeSettle, _ := strconv.Atoi(readEnv("HEDGE_SETTLE", "1000"))
+	maxAmount := readEnv("MAX_AMOUNT", "1000000")
+	correlationIDFormat := readEnv("CORRELATION_ID_FORMAT", handler.CorrelationIDUUID)
+	deadlineMultiplier, _ := strconv.ParseFloat(readEnv("DEADLINE_MULTIPLIER", "4"), 64)
+	deadlineMin, _ := strconv.Atoi(readEnv("DEADLINE_MIN", "2000"))
+	deadlineMax, _ := strconv.Atoi(readEnv("DEADLINE_MAX", "5000"))
 
-	jobs := make(chan model.PaymentRequest, jobsBufferSize)
-	r := repository.NewRepository()
-	c := client.NewClient(defaultUrl, fallbackUrl, healthUrl)
-	h := handler.NewHandler(jobs, r, c, otherUrl)
-	w := worker.NewWorker(jobs, r, c, numWorkers, defaultTolerance, semaphoreSize, workerSleep)
+	if processorsSpec == "" {
+		processorsSpec = fmt.Sprintf("default|%s|%g|0,fallback|%s|%g|1", defaultUrl, defaultFee, fallbackUrl, fallbackFee)
+	}
+	processors, err := client.ParseProcessors(processorsSpec)
+	if err != nil {
+		log.Fatalf("Processor config error: %v", err)
+	}
+
+	walOptions := wal.Options{
+		SegmentSize:  walSegmentSize,
+		SyncInterval: time.Duration(walSyncInterval) * time.Millisecond,
+	}
+	var paymentLog, jobLog, deadLog *wal.Log
+	if walDir != "" {
+		paymentLog, err = wal.Open(filepath.Join(walDir, "payments"), walOptions)
+		if err != nil {
+			log.Fatalf("Payment log open error: %v", err)
+		}
+		jobLog, err = wal.Open(filepath.Join(walDir, "queue"), walOptions)
+		if err != nil {
+			log.Fatalf("Job spool open error: %v", err)
+		}
+		deadLog, err = wal.Open(filepath.Join(walDir, "dead-letters"), walOptions)
+		if err != nil {
+			log.Fatalf("Dead letter log open error: %v", err)
+		}
+		// Jobs are acked only after their payment or dead letter is written.
+		jobLog.Upstream = []*wal.Log{paymentLog, deadLog}
+	}
+
+	q, err := queue.NewQueue(jobsBufferSize, jobLog, walMaxSegments)
+	if err != nil {
+		log.Fatalf("Job spool replay error: %v", err)
+	}
+	breakerSettings := client.BreakerSettings{
+		FailureThreshold: breakerFailureThreshold,
+		OpenTimeout:      time.Duration(breakerOpenTimeout) * time.Millisecond,
+		HalfOpenProbes:   breakerHalfOpenProbes,
+	}
+	limiterSettings := client.LimiterSettings{
+		Initial:   limitInitial,
+		Min:       limitMin,
+		Max:       limitMax,
+		Tolerance: limitTolerance,
+		Backoff:   limitBackoff,
+	}
+	c, err := client.NewClient(processors, healthUrl, time.Duration(passiveWindow)*time.Millisecond, breakerSettings, limiterSettings)
+	if err != nil {
+		log.Fatalf("Client config error: %v", err)
+	}
+	if healthUrl == "" {
+		c.Prober = client.NewProber(c, time.Duration(healthInterval)*time.Millisecond, healthThreshold)
+		c.Prober.Start()
+	}
+	r, err := repository.NewRepository(paymentLog, time.Duration(dedupeRetention)*time.Millisecond, logTransitions, c.ProcessorNames())
+	if err != nil {
+		log.Fatalf("Payment log replay error: %v", err)
+	}
+	dl, err := repository.NewDeadLetterStore(deadLog, walMaxSegments)
+	if err != nil {
+		log.Fatalf("Dead letter log replay error: %v", err)
+	}
+	for _, req := range q.Pending() {
+		r.Reserve(req)
+		r.Recover(req.CorrelationID)
+	}
+	for _, letter := range dl.List() {
+		r.Reserve(model.PaymentRequest{CorrelationID: letter.CorrelationID, Amount: letter.Amount, Currency: letter.Currency})
+		r.Transition(letter.CorrelationID, model.StateDead)
+	}
+	h := handler.NewHandler(q, r, dl, c, otherUrl)
+	h.Shard = intakeShard
+	h.Validator.CorrelationIDFormat = correlationIDFormat
+	if maxAmount != "" {
+		h.Validator.MaxAmount, err = model.ParseMoney(maxAmount)
+		if err != nil {
+			log.Fatalf("MAX_AMOUNT error: %v", err)
+		}
+	}
+	retryPolicy := worker.RetryPolicy{
+		InitialDelay: time.Duration(retryInitialDelay) * time.Millisecond,
+		Multiplier:   retryMultiplier,
+		Jitter:       retryJitter,
+		MaxAttempts:  retryMaxAttempts,
+		MaxAge:       time.Duration(retryMaxAge) * time.Millisecond,
+	}
+	passivePolicy := worker.PassivePolicy{
+		MinSamples:   passiveMinSamples,
+		MaxErrorRate: passiveMaxErrorRate,
+	}
+	// Without explicit weights everything goes to the first processor.
+	weights := make([]float64, len(processors))
+	weights[0] = 1
+	if routerWeights != "" {
+		for i, weight := range strings.Split(routerWeights, ",") {
+			if i < len(weights) {
+				weights[i], _ = strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			}
+		}
+	}
+	router, err := worker.NewRouter(routerName, time.Duration(defaultTolerance)*time.Millisecond, latencyPenalty, weights)
+	if err != nil {
+		log.Fatalf("Router error: %v", err)
+	}
+	w := worker.NewWorker(q, r, dl, c, router, numWorkers, time.Duration(reconcileInterval)*time.Millisecond, retryPolicy, retryQueueSize, passivePolicy)
+	w.Hedge = worker.HedgePolicy{
+		Percentile: hedgePercentile,
+		MinDelay:   time.Duration(hedgeMinDelay) * time.Millisecond,
+		Settle:     time.Duration(hedgeSettle) * time.Millisecond,
+	}
+	w.Deadline = worker.DeadlinePolicy{
+		Multiplier: deadlineMultiplier,
+		Min:        time.Duration(deadlineMin) * time.Millisecond,
+		Max:        time.Duration(deadlineMax) * time.Millisecond,
+	}
 
 	server := &fasthttp.Server{
 		Handler: func(ctx *fasthttp.RequestCtx) {
@@ -55,7 +209,21 @@ func main() {
 				h.GetSummary(ctx)
 			case "/purge-payments":
 				h.PurgePayments(ctx)
+			case "/health":
+				h.ServiceHealth(ctx)
+			case "/admin/breakers":
+				h.AdminBreakers(ctx)
+			case "/admin/limits":
+				h.AdminLimits(ctx)
 			default:
+				if bytes.HasPrefix(ctx.Path(), []byte("/payments/")) {
+					h.GetPayment(ctx)
+					return
+				}
+				if bytes.HasPrefix(ctx.Path(), []byte("/admin/dead-letters")) {
+					h.AdminDeadLetters(ctx)
+					return
+				}
 				ctx.SetStatusCode(fasthttp.StatusNotFound)
 			}
 		},
@@ -74,10 +242,20 @@ func main() {
 
 	<-ctx.Done()
 	log.Println("Shutdown signal received")
-	_, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if err := server.Shutdown(); err != nil {
+	if err := server.ShutdownWithContext(shutdownCtx); err != nil {
 		log.Printf("HTTP shutdown error: %v", err)
 	}
+	w.Stop(shutdownCtx)
+	if err := q.Close(); err != nil {
+		log.Printf("Job spool close error: %v", err)
+	}
+	if err := dl.Close(); err != nil {
+		log.Printf("Dead letter log close error: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		log.Printf("Payment log close error: %v", err)
+	}
 	log.Println("Application closed")
 }
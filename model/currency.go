@@ -0,0 +1,88 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 code. The empty currency is DefaultCurrency.
+type Currency string
+
+// DefaultCurrency is assumed for payments that name no currency.
+const DefaultCurrency Currency = "BRL"
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// minorUnits holds the decimal places of each accepted currency.
+var minorUnits = map[Currency]int{
+	"ARS": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"COP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"OMR": 3,
+	"PYG": 0,
+	"USD": 2,
+	"UYU": 2,
+}
+
+// OrDefault returns the currency in upper case, or DefaultCurrency when it
+// is empty.
+func (c Currency) OrDefault() Currency {
+	if c == "" {
+		return DefaultCurrency
+	}
+	return Currency(strings.ToUpper(string(c)))
+}
+
+// MinorUnits returns the currency's decimal places and whether it is known.
+func (c Currency) MinorUnits() (int, bool) {
+	units, ok := minorUnits[c.OrDefault()]
+	return units, ok
+}
+
+// Check reports an error when the currency is unknown or amount has more
+// decimal places than the currency allows.
+func (c Currency) Check(amount Money) error {
+	units, ok := c.MinorUnits()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, string(c))
+	}
+	if amount%c.step(units) != 0 {
+		return fmt.Errorf("%w: %s %s has more than %d decimal places", ErrInvalidAmount, amount, c.OrDefault(), units)
+	}
+	return nil
+}
+
+// Round rounds amount half away from zero to the currency's minor unit.
+func (c Currency) Round(amount Money) Money {
+	units, ok := c.MinorUnits()
+	if !ok {
+		return amount
+	}
+	step := c.step(units)
+	half := step / 2
+	if amount < 0 {
+		return -((-amount + half) / step * step)
+	}
+	return (amount + half) / step * step
+}
+
+func (c Currency) step(units int) Money {
+	step := Money(1)
+	for i := units; i < MoneyScale; i++ {
+		step *= 10
+	}
+	return step
+}
@@ -3,24 +3,46 @@ package model
 import "time"
 
 type PaymentRequest struct {
-	CorrelationID string  `json:"correlationId"`
-	Amount        float64 `json:"amount"`
+	CorrelationID string   `json:"correlationId"`
+	Amount        Money    `json:"amount"`
+	Currency      Currency `json:"currency,omitempty"`
 }
 
 type PaymentEvent struct {
-	CorrelationID string  `json:"correlationId"`
-	Amount        float64 `json:"amount"`
-	RequestedAt   string  `json:"requestedAt"`
+	CorrelationID string   `json:"correlationId"`
+	Amount        Money    `json:"amount"`
+	Currency      Currency `json:"currency,omitempty"`
+	RequestedAt   string   `json:"requestedAt"`
 }
 
 type Summary struct {
-	TotalRequests int     `json:"totalRequests"`
-	TotalAmount   float64 `json:"totalAmount"`
+	TotalRequests int   `json:"totalRequests"`
+	TotalAmount   Money `json:"totalAmount"`
+	EstimatedFees Money `json:"estimatedFees"`
 }
 
+// SummaryResponse carries one summary per processor name in the default
+// currency, and per processor name and currency in Currencies. Default and
+// Fallback repeat the processors of those names for older clients.
 type SummaryResponse struct {
-	Default  Summary `json:"default"`
-	Fallback Summary `json:"fallback"`
+	Default    Summary                         `json:"default"`
+	Fallback   Summary                         `json:"fallback"`
+	Processors map[string]Summary              `json:"processors"`
+	Currencies map[string]map[Currency]Summary `json:"currencies,omitempty"`
+}
+
+// ErrorResponse is the body of every error response. Fields lists the
+// request fields that failed validation; State is the state of the earlier
+// payment a duplicate conflicts with.
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+	State  PaymentState `json:"state,omitempty"`
+}
+
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
 }
 
 type ProcessorHealthResponse struct {
@@ -30,15 +52,80 @@ type ProcessorHealthResponse struct {
 
 type Payment struct {
 	CorrelationID string    `json:"correlationId"`
-	Amount        float64   `json:"amount"`
+	Amount        Money     `json:"amount"`
+	Currency      Currency  `json:"currency,omitempty"`
 	RequestedAt   time.Time `json:"requestedAt"`
 	Processor     int       `json:"processor"`
 }
 
 type ServiceHealthResponse struct {
-	DefaultHealth       bool `json:"defaultHeath"`
-	FallbackHealth      bool `json:"fallbackHealth"`
-	DefaultMinResponse  int  `json:"defaultMinResponse"`
-	FallbackMinResponse int  `json:"fallbackMinResponse"`
-	NextCheck           int  `json:"nextCheck"`
+	DefaultHealth       bool              `json:"defaultHeath"`
+	FallbackHealth      bool              `json:"fallbackHealth"`
+	DefaultMinResponse  int               `json:"defaultMinResponse"`
+	FallbackMinResponse int               `json:"fallbackMinResponse"`
+	NextCheck           int               `json:"nextCheck"`
+	Processors          []ProcessorHealth `json:"processors,omitempty"`
+}
+
+type ProcessorHealth struct {
+	Name        string `json:"name"`
+	Healthy     bool   `json:"healthy"`
+	MinResponse int    `json:"minResponse"`
 }
+
+type PaymentState string
+
+const (
+	StateReceived        PaymentState = "received"
+	StateDispatched      PaymentState = "dispatched"
+	StateConfirmed       PaymentState = "confirmed"
+	StateFailedRetryable PaymentState = "failed-retryable"
+	StateUnknownOutcome  PaymentState = "unknown-outcome"
+	StateDead            PaymentState = "dead"
+)
+
+type PaymentStatusResponse struct {
+	CorrelationID string       `json:"correlationId"`
+	Amount        Money        `json:"amount"`
+	Currency      Currency     `json:"currency,omitempty"`
+	RequestedAt   string       `json:"requestedAt,omitempty"`
+	Processor     string       `json:"processor,omitempty"`
+	State         PaymentState `json:"state"`
+}
+
+type ProcessorPaymentResponse struct {
+	CorrelationID string `json:"correlationId"`
+	Amount        Money  `json:"amount"`
+	RequestedAt   string `json:"requestedAt"`
+}
+
+type DeadLetter struct {
+	CorrelationID string   `json:"correlationId"`
+	Amount        Money    `json:"amount"`
+	Currency      Currency `json:"currency,omitempty"`
+	Reason        string   `json:"reason"`
+	Attempts      int      `json:"attempts"`
+	DeadAt        string   `json:"deadAt"`
+}
+
+//easyjson:json
+type DeadLetters []DeadLetter
+
+type BreakerStatus struct {
+	Processor string `json:"processor"`
+	Url       string `json:"url"`
+	State     string `json:"state"`
+	Failures  int    `json:"failures"`
+}
+
+//easyjson:json
+type BreakerStatuses []BreakerStatus
+
+type LimiterStatus struct {
+	Processor string `json:"processor"`
+	Limit     int    `json:"limit"`
+	InFlight  int    `json:"inFlight"`
+}
+
+//easyjson:json
+type LimiterStatuses []LimiterStatus
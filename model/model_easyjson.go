@@ -40,6 +40,60 @@ func easyjsonC80ae7adDecodeRb2025V3Model(in *jlexer.Lexer, out *SummaryResponse)
 			(out.Default).UnmarshalEasyJSON(in)
 		case "fallback":
 			(out.Fallback).UnmarshalEasyJSON(in)
+		case "processors":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Processors = make(map[string]Summary)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 Summary
+					(v1).UnmarshalEasyJSON(in)
+					(out.Processors)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "currencies":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Currencies = make(map[string]map[Currency]Summary)
+				} else {
+					out.Currencies = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v2 map[Currency]Summary
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						in.Delim('{')
+						if !in.IsDelim('}') {
+							v2 = make(map[Currency]Summary)
+						} else {
+							v2 = nil
+						}
+						for !in.IsDelim('}') {
+							key := Currency(in.String())
+							in.WantColon()
+							var v3 Summary
+							(v3).UnmarshalEasyJSON(in)
+							(v2)[key] = v3
+							in.WantComma()
+						}
+						in.Delim('}')
+					}
+					(out.Currencies)[key] = v2
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -64,6 +118,62 @@ func easyjsonC80ae7adEncodeRb2025V3Model(out *jwriter.Writer, in SummaryResponse
 		out.RawString(prefix)
 		(in.Fallback).MarshalEasyJSON(out)
 	}
+	{
+		const prefix string = ",\"processors\":"
+		out.RawString(prefix)
+		if in.Processors == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v4First := true
+			for v4Name, v4Value := range in.Processors {
+				if v4First {
+					v4First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v4Name))
+				out.RawByte(':')
+				(v4Value).MarshalEasyJSON(out)
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Currencies) != 0 {
+		const prefix string = ",\"currencies\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Currencies {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				if v5Value == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+					out.RawString(`null`)
+				} else {
+					out.RawByte('{')
+					v6First := true
+					for v6Name, v6Value := range v5Value {
+						if v6First {
+							v6First = false
+						} else {
+							out.RawByte(',')
+						}
+						out.String(string(v6Name))
+						out.RawByte(':')
+						(v6Value).MarshalEasyJSON(out)
+					}
+					out.RawByte('}')
+				}
+			}
+			out.RawByte('}')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -112,7 +222,9 @@ func easyjsonC80ae7adDecodeRb2025V3Model1(in *jlexer.Lexer, out *Summary) {
 		case "totalRequests":
 			out.TotalRequests = int(in.Int())
 		case "totalAmount":
-			out.TotalAmount = float64(in.Float64())
+			(out.TotalAmount).UnmarshalEasyJSON(in)
+		case "estimatedFees":
+			(out.EstimatedFees).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -135,7 +247,12 @@ func easyjsonC80ae7adEncodeRb2025V3Model1(out *jwriter.Writer, in Summary) {
 	{
 		const prefix string = ",\"totalAmount\":"
 		out.RawString(prefix)
-		out.Float64(float64(in.TotalAmount))
+		(in.TotalAmount).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"estimatedFees\":"
+		out.RawString(prefix)
+		(in.EstimatedFees).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
@@ -192,6 +309,29 @@ func easyjsonC80ae7adDecodeRb2025V3Model2(in *jlexer.Lexer, out *ServiceHealthRe
 			out.FallbackMinResponse = int(in.Int())
 		case "nextCheck":
 			out.NextCheck = int(in.Int())
+		case "processors":
+			if in.IsNull() {
+				in.Skip()
+				out.Processors = nil
+			} else {
+				in.Delim('[')
+				if out.Processors == nil {
+					if !in.IsDelim(']') {
+						out.Processors = make([]ProcessorHealth, 0, 2)
+					} else {
+						out.Processors = []ProcessorHealth{}
+					}
+				} else {
+					out.Processors = (out.Processors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 ProcessorHealth
+					(v7).UnmarshalEasyJSON(in)
+					out.Processors = append(out.Processors, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -231,6 +371,20 @@ func easyjsonC80ae7adEncodeRb2025V3Model2(out *jwriter.Writer, in ServiceHealthR
 		out.RawString(prefix)
 		out.Int(int(in.NextCheck))
 	}
+	if len(in.Processors) != 0 {
+		const prefix string = ",\"processors\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.Processors {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
 	out.RawByte('}')
 }
 
@@ -257,7 +411,87 @@ func (v *ServiceHealthResponse) UnmarshalJSON(data []byte) error {
 func (v *ServiceHealthResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC80ae7adDecodeRb2025V3Model2(l, v)
 }
-func easyjsonC80ae7adDecodeRb2025V3Model3(in *jlexer.Lexer, out *ProcessorHealthResponse) {
+func easyjsonC80ae7adDecodeRb2025V3Model3(in *jlexer.Lexer, out *ProcessorPaymentResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationID = string(in.String())
+		case "amount":
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "requestedAt":
+			out.RequestedAt = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model3(out *jwriter.Writer, in ProcessorPaymentResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationID))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"requestedAt\":"
+		out.RawString(prefix)
+		out.String(string(in.RequestedAt))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProcessorPaymentResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProcessorPaymentResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProcessorPaymentResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProcessorPaymentResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model3(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model4(in *jlexer.Lexer, out *ProcessorHealthResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -290,7 +524,7 @@ func easyjsonC80ae7adDecodeRb2025V3Model3(in *jlexer.Lexer, out *ProcessorHealth
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeRb2025V3Model3(out *jwriter.Writer, in ProcessorHealthResponse) {
+func easyjsonC80ae7adEncodeRb2025V3Model4(out *jwriter.Writer, in ProcessorHealthResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -310,27 +544,208 @@ func easyjsonC80ae7adEncodeRb2025V3Model3(out *jwriter.Writer, in ProcessorHealt
 // MarshalJSON supports json.Marshaler interface
 func (v ProcessorHealthResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeRb2025V3Model3(&w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v ProcessorHealthResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeRb2025V3Model3(w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *ProcessorHealthResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeRb2025V3Model3(&r, v)
+	easyjsonC80ae7adDecodeRb2025V3Model4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *ProcessorHealthResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeRb2025V3Model3(l, v)
+	easyjsonC80ae7adDecodeRb2025V3Model4(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model5(in *jlexer.Lexer, out *ProcessorHealth) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "healthy":
+			out.Healthy = bool(in.Bool())
+		case "minResponse":
+			out.MinResponse = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model5(out *jwriter.Writer, in ProcessorHealth) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"healthy\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Healthy))
+	}
+	{
+		const prefix string = ",\"minResponse\":"
+		out.RawString(prefix)
+		out.Int(int(in.MinResponse))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ProcessorHealth) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ProcessorHealth) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ProcessorHealth) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ProcessorHealth) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model5(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model6(in *jlexer.Lexer, out *PaymentStatusResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationID = string(in.String())
+		case "amount":
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "currency":
+			out.Currency = Currency(in.String())
+		case "requestedAt":
+			out.RequestedAt = string(in.String())
+		case "processor":
+			out.Processor = string(in.String())
+		case "state":
+			out.State = PaymentState(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model6(out *jwriter.Writer, in PaymentStatusResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationID))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
+	}
+	if in.RequestedAt != "" {
+		const prefix string = ",\"requestedAt\":"
+		out.RawString(prefix)
+		out.String(string(in.RequestedAt))
+	}
+	if in.Processor != "" {
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix)
+		out.String(string(in.Processor))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PaymentStatusResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PaymentStatusResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PaymentStatusResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PaymentStatusResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model6(l, v)
 }
-func easyjsonC80ae7adDecodeRb2025V3Model4(in *jlexer.Lexer, out *PaymentRequest) {
+func easyjsonC80ae7adDecodeRb2025V3Model7(in *jlexer.Lexer, out *PaymentRequest) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -352,7 +767,9 @@ func easyjsonC80ae7adDecodeRb2025V3Model4(in *jlexer.Lexer, out *PaymentRequest)
 		case "correlationId":
 			out.CorrelationID = string(in.String())
 		case "amount":
-			out.Amount = float64(in.Float64())
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "currency":
+			out.Currency = Currency(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -363,7 +780,7 @@ func easyjsonC80ae7adDecodeRb2025V3Model4(in *jlexer.Lexer, out *PaymentRequest)
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeRb2025V3Model4(out *jwriter.Writer, in PaymentRequest) {
+func easyjsonC80ae7adEncodeRb2025V3Model7(out *jwriter.Writer, in PaymentRequest) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -375,7 +792,12 @@ func easyjsonC80ae7adEncodeRb2025V3Model4(out *jwriter.Writer, in PaymentRequest
 	{
 		const prefix string = ",\"amount\":"
 		out.RawString(prefix)
-		out.Float64(float64(in.Amount))
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
 	}
 	out.RawByte('}')
 }
@@ -383,27 +805,27 @@ func easyjsonC80ae7adEncodeRb2025V3Model4(out *jwriter.Writer, in PaymentRequest
 // MarshalJSON supports json.Marshaler interface
 func (v PaymentRequest) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeRb2025V3Model4(&w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v PaymentRequest) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeRb2025V3Model4(w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *PaymentRequest) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeRb2025V3Model4(&r, v)
+	easyjsonC80ae7adDecodeRb2025V3Model7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *PaymentRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeRb2025V3Model4(l, v)
+	easyjsonC80ae7adDecodeRb2025V3Model7(l, v)
 }
-func easyjsonC80ae7adDecodeRb2025V3Model5(in *jlexer.Lexer, out *PaymentEvent) {
+func easyjsonC80ae7adDecodeRb2025V3Model8(in *jlexer.Lexer, out *PaymentEvent) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -425,7 +847,9 @@ func easyjsonC80ae7adDecodeRb2025V3Model5(in *jlexer.Lexer, out *PaymentEvent) {
 		case "correlationId":
 			out.CorrelationID = string(in.String())
 		case "amount":
-			out.Amount = float64(in.Float64())
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "currency":
+			out.Currency = Currency(in.String())
 		case "requestedAt":
 			out.RequestedAt = string(in.String())
 		default:
@@ -438,7 +862,7 @@ func easyjsonC80ae7adDecodeRb2025V3Model5(in *jlexer.Lexer, out *PaymentEvent) {
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeRb2025V3Model5(out *jwriter.Writer, in PaymentEvent) {
+func easyjsonC80ae7adEncodeRb2025V3Model8(out *jwriter.Writer, in PaymentEvent) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -450,7 +874,12 @@ func easyjsonC80ae7adEncodeRb2025V3Model5(out *jwriter.Writer, in PaymentEvent)
 	{
 		const prefix string = ",\"amount\":"
 		out.RawString(prefix)
-		out.Float64(float64(in.Amount))
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
 	}
 	{
 		const prefix string = ",\"requestedAt\":"
@@ -463,27 +892,27 @@ func easyjsonC80ae7adEncodeRb2025V3Model5(out *jwriter.Writer, in PaymentEvent)
 // MarshalJSON supports json.Marshaler interface
 func (v PaymentEvent) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeRb2025V3Model5(&w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v PaymentEvent) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeRb2025V3Model5(w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *PaymentEvent) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeRb2025V3Model5(&r, v)
+	easyjsonC80ae7adDecodeRb2025V3Model8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *PaymentEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeRb2025V3Model5(l, v)
+	easyjsonC80ae7adDecodeRb2025V3Model8(l, v)
 }
-func easyjsonC80ae7adDecodeRb2025V3Model6(in *jlexer.Lexer, out *Payment) {
+func easyjsonC80ae7adDecodeRb2025V3Model9(in *jlexer.Lexer, out *Payment) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -505,7 +934,9 @@ func easyjsonC80ae7adDecodeRb2025V3Model6(in *jlexer.Lexer, out *Payment) {
 		case "correlationId":
 			out.CorrelationID = string(in.String())
 		case "amount":
-			out.Amount = float64(in.Float64())
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "currency":
+			out.Currency = Currency(in.String())
 		case "requestedAt":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.RequestedAt).UnmarshalJSON(data))
@@ -522,7 +953,7 @@ func easyjsonC80ae7adDecodeRb2025V3Model6(in *jlexer.Lexer, out *Payment) {
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeRb2025V3Model6(out *jwriter.Writer, in Payment) {
+func easyjsonC80ae7adEncodeRb2025V3Model9(out *jwriter.Writer, in Payment) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -534,7 +965,12 @@ func easyjsonC80ae7adEncodeRb2025V3Model6(out *jwriter.Writer, in Payment) {
 	{
 		const prefix string = ",\"amount\":"
 		out.RawString(prefix)
-		out.Float64(float64(in.Amount))
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
 	}
 	{
 		const prefix string = ",\"requestedAt\":"
@@ -552,23 +988,672 @@ func easyjsonC80ae7adEncodeRb2025V3Model6(out *jwriter.Writer, in Payment) {
 // MarshalJSON supports json.Marshaler interface
 func (v Payment) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeRb2025V3Model6(&w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Payment) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeRb2025V3Model6(w, v)
+	easyjsonC80ae7adEncodeRb2025V3Model9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Payment) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeRb2025V3Model6(&r, v)
+	easyjsonC80ae7adDecodeRb2025V3Model9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Payment) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeRb2025V3Model6(l, v)
+	easyjsonC80ae7adDecodeRb2025V3Model9(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model10(in *jlexer.Lexer, out *LimiterStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(LimiterStatuses, 0, 2)
+			} else {
+				*out = LimiterStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v10 LimiterStatus
+			(v10).UnmarshalEasyJSON(in)
+			*out = append(*out, v10)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model10(out *jwriter.Writer, in LimiterStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v11, v12 := range in {
+			if v11 > 0 {
+				out.RawByte(',')
+			}
+			(v12).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LimiterStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LimiterStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LimiterStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LimiterStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model10(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model11(in *jlexer.Lexer, out *LimiterStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "processor":
+			out.Processor = string(in.String())
+		case "limit":
+			out.Limit = int(in.Int())
+		case "inFlight":
+			out.InFlight = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model11(out *jwriter.Writer, in LimiterStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Processor))
+	}
+	{
+		const prefix string = ",\"limit\":"
+		out.RawString(prefix)
+		out.Int(int(in.Limit))
+	}
+	{
+		const prefix string = ",\"inFlight\":"
+		out.RawString(prefix)
+		out.Int(int(in.InFlight))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LimiterStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LimiterStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LimiterStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LimiterStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model11(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model12(in *jlexer.Lexer, out *FieldError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "field":
+			out.Field = string(in.String())
+		case "reason":
+			out.Reason = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model12(out *jwriter.Writer, in FieldError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"field\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Field))
+	}
+	{
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v FieldError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v FieldError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *FieldError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *FieldError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model12(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model13(in *jlexer.Lexer, out *ErrorResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "error":
+			out.Error = string(in.String())
+		case "fields":
+			if in.IsNull() {
+				in.Skip()
+				out.Fields = nil
+			} else {
+				in.Delim('[')
+				if out.Fields == nil {
+					if !in.IsDelim(']') {
+						out.Fields = make([]FieldError, 0, 2)
+					} else {
+						out.Fields = []FieldError{}
+					}
+				} else {
+					out.Fields = (out.Fields)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 FieldError
+					(v13).UnmarshalEasyJSON(in)
+					out.Fields = append(out.Fields, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "state":
+			out.State = PaymentState(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model13(out *jwriter.Writer, in ErrorResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Error))
+	}
+	if len(in.Fields) != 0 {
+		const prefix string = ",\"fields\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v14, v15 := range in.Fields {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				(v15).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.State != "" {
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ErrorResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ErrorResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ErrorResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ErrorResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model13(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model14(in *jlexer.Lexer, out *DeadLetters) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(DeadLetters, 0, 0)
+			} else {
+				*out = DeadLetters{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v16 DeadLetter
+			(v16).UnmarshalEasyJSON(in)
+			*out = append(*out, v16)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model14(out *jwriter.Writer, in DeadLetters) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v17, v18 := range in {
+			if v17 > 0 {
+				out.RawByte(',')
+			}
+			(v18).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeadLetters) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeadLetters) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeadLetters) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeadLetters) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model14(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model15(in *jlexer.Lexer, out *DeadLetter) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "correlationId":
+			out.CorrelationID = string(in.String())
+		case "amount":
+			(out.Amount).UnmarshalEasyJSON(in)
+		case "currency":
+			out.Currency = Currency(in.String())
+		case "reason":
+			out.Reason = string(in.String())
+		case "attempts":
+			out.Attempts = int(in.Int())
+		case "deadAt":
+			out.DeadAt = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model15(out *jwriter.Writer, in DeadLetter) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"correlationId\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.CorrelationID))
+	}
+	{
+		const prefix string = ",\"amount\":"
+		out.RawString(prefix)
+		(in.Amount).MarshalEasyJSON(out)
+	}
+	if in.Currency != "" {
+		const prefix string = ",\"currency\":"
+		out.RawString(prefix)
+		out.String(string(in.Currency))
+	}
+	{
+		const prefix string = ",\"reason\":"
+		out.RawString(prefix)
+		out.String(string(in.Reason))
+	}
+	{
+		const prefix string = ",\"attempts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Attempts))
+	}
+	{
+		const prefix string = ",\"deadAt\":"
+		out.RawString(prefix)
+		out.String(string(in.DeadAt))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v DeadLetter) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v DeadLetter) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *DeadLetter) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *DeadLetter) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model15(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model16(in *jlexer.Lexer, out *BreakerStatuses) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(BreakerStatuses, 0, 1)
+			} else {
+				*out = BreakerStatuses{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v19 BreakerStatus
+			(v19).UnmarshalEasyJSON(in)
+			*out = append(*out, v19)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model16(out *jwriter.Writer, in BreakerStatuses) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v20, v21 := range in {
+			if v20 > 0 {
+				out.RawByte(',')
+			}
+			(v21).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BreakerStatuses) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BreakerStatuses) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BreakerStatuses) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BreakerStatuses) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model16(l, v)
+}
+func easyjsonC80ae7adDecodeRb2025V3Model17(in *jlexer.Lexer, out *BreakerStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "processor":
+			out.Processor = string(in.String())
+		case "url":
+			out.Url = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "failures":
+			out.Failures = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC80ae7adEncodeRb2025V3Model17(out *jwriter.Writer, in BreakerStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"processor\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Processor))
+	}
+	{
+		const prefix string = ",\"url\":"
+		out.RawString(prefix)
+		out.String(string(in.Url))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"failures\":"
+		out.RawString(prefix)
+		out.Int(int(in.Failures))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BreakerStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC80ae7adEncodeRb2025V3Model17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BreakerStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC80ae7adEncodeRb2025V3Model17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BreakerStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC80ae7adDecodeRb2025V3Model17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BreakerStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC80ae7adDecodeRb2025V3Model17(l, v)
 }
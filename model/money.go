@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// MoneyScale is the number of decimal places a Money amount keeps, enough
+// for the currency with the most minor-unit digits.
+const MoneyScale = 3
+
+// moneyUnit is one currency unit in Money.
+const moneyUnit = 1000
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrAmountNotNumber is returned for an amount that is not a JSON number,
+// e.g. a quoted one.
+var ErrAmountNotNumber = fmt.Errorf("%w: must be a number", ErrInvalidAmount)
+
+// Money is an exact amount in thousandths of a currency unit. In JSON it is a
+// plain decimal number, e.g. 19.90. Currency.Check tells whether an amount
+// fits its currency's minor unit.
+type Money int64
+
+// ParseMoney parses a decimal such as "19.9" or "-0.05". More than
+// MoneyScale significant decimal places, exponents and overflow are errors.
+func ParseMoney(s string) (Money, error) {
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" || strings.ContainsAny(whole, "+-") || strings.ContainsAny(frac, "+-") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > MoneyScale {
+		return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, s, MoneyScale)
+	}
+	frac += strings.Repeat("0", MoneyScale-len(frac))
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	fraction, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if units > (math.MaxInt64-fraction)/moneyUnit {
+		return 0, fmt.Errorf("%w: %q is too large", ErrInvalidAmount, s)
+	}
+	m := Money(units*moneyUnit + fraction)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// String formats the amount with two decimal places, or three when the last
+// one is not zero.
+func (m Money) String() string {
+	sign := ""
+	n := int64(m)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	if n%10 == 0 {
+		return fmt.Sprintf("%s%d.%02d", sign, n/moneyUnit, n%moneyUnit/10)
+	}
+	return fmt.Sprintf("%s%d.%03d", sign, n/moneyUnit, n%moneyUnit)
+}
+
+// Float64 returns the amount in currency units, for estimates only.
+func (m Money) Float64() float64 {
+	return float64(m) / moneyUnit
+}
+
+// Mul returns the amount times rate, rounded to the nearest thousandth.
+func (m Money) Mul(rate float64) Money {
+	return Money(math.Round(float64(m) * rate))
+}
+
+func (m Money) MarshalEasyJSON(w *jwriter.Writer) {
+	w.RawString(m.String())
+}
+
+func (m *Money) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	if l.CurrentToken() != jlexer.TokenNumber {
+		if l.Ok() {
+			l.AddError(ErrAmountNotNumber)
+		}
+		return
+	}
+	raw := string(l.Raw())
+	if !l.Ok() {
+		return
+	}
+	parsed, err := ParseMoney(raw)
+	if err != nil {
+		l.AddError(err)
+		return
+	}
+	*m = parsed
+}
@@ -0,0 +1,207 @@
+package queue
+
+import (
+	"errors"
+	"log"
+	"rb2025-v3/model"
+	"rb2025-v3/wal"
+	"sync"
+
+	"github.com/mailru/easyjson"
+)
+
+const (
+	recordEnqueue byte = 'E'
+	recordAck     byte = 'K'
+)
+
+// Queue is the job channel backed by an on-disk spool. Every accepted request
+// is logged before it is handed to the workers and stays pending until Ack.
+type Queue struct {
+	Jobs        chan model.PaymentRequest
+	Log         *wal.Log
+	MaxSegments int
+	mu          sync.Mutex
+	pending     map[string]model.PaymentRequest
+	compacting  bool
+}
+
+// NewQueue replays the spool in jobLog, when given, and feeds every request
+// that was never acknowledged back into Jobs.
+func NewQueue(size int, jobLog *wal.Log, maxSegments int) (*Queue, error) {
+	q := &Queue{
+		Jobs:        make(chan model.PaymentRequest, size),
+		Log:         jobLog,
+		MaxSegments: maxSegments,
+		pending:     make(map[string]model.PaymentRequest),
+	}
+	if jobLog == nil {
+		return q, nil
+	}
+	err := jobLog.Replay(func(rec []byte) error {
+		if len(rec) == 0 {
+			return errors.New("empty queue record")
+		}
+		switch rec[0] {
+		case recordEnqueue:
+			var req model.PaymentRequest
+			if err := easyjson.Unmarshal(rec[1:], &req); err != nil {
+				return err
+			}
+			q.pending[req.CorrelationID] = req
+		case recordAck:
+			delete(q.pending, string(rec[1:]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobLog.Start()
+	if len(q.pending) > 0 {
+		log.Printf("Replaying %d pending jobs", len(q.pending))
+		replayed := make([]model.PaymentRequest, 0, len(q.pending))
+		for _, req := range q.pending {
+			replayed = append(replayed, req)
+		}
+		go func() {
+			for _, req := range replayed {
+				q.Jobs <- req
+			}
+		}()
+	}
+	return q, nil
+}
+
+// Push spools req and hands it to the workers once the spool record is
+// durable. It returns false without keeping anything when the record cannot
+// be made durable or the job channel is full.
+func (q *Queue) Push(req model.PaymentRequest) bool {
+	if q.Log == nil {
+		select {
+		case q.Jobs <- req:
+			return true
+		default:
+			return false
+		}
+	}
+	rec, err := encodeRequest(req)
+	if err != nil {
+		log.Printf("Job encode error: %v", err)
+		return false
+	}
+	q.mu.Lock()
+	if err := q.Log.Append(rec); err != nil {
+		q.mu.Unlock()
+		log.Printf("Job spool append error: %v", err)
+		return false
+	}
+	// Pending before the commit, so a compaction meanwhile keeps it.
+	q.pending[req.CorrelationID] = req
+	q.mu.Unlock()
+	if err := q.Log.Commit(); err != nil {
+		log.Printf("Job spool sync error: %v", err)
+		q.drop(req.CorrelationID)
+		return false
+	}
+	select {
+	case q.Jobs <- req:
+		return true
+	default:
+		q.drop(req.CorrelationID)
+		return false
+	}
+}
+
+// drop forgets a request that was spooled but not accepted.
+func (q *Queue) drop(correlationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, correlationID)
+	q.appendAck(correlationID)
+}
+
+// Pending returns the requests spooled but not yet acknowledged.
+func (q *Queue) Pending() []model.PaymentRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := make([]model.PaymentRequest, 0, len(q.pending))
+	for _, req := range q.pending {
+		pending = append(pending, req)
+	}
+	return pending
+}
+
+// Ack removes a request from the spool once its payment has been recorded.
+func (q *Queue) Ack(correlationID string) {
+	if q.Log == nil {
+		return
+	}
+	q.mu.Lock()
+	delete(q.pending, correlationID)
+	q.appendAck(correlationID)
+	compact := !q.compacting && q.MaxSegments > 0 && q.Log.Segments() > q.MaxSegments
+	if compact {
+		q.compacting = true
+	}
+	q.mu.Unlock()
+	if compact {
+		go q.compact()
+	}
+}
+
+func (q *Queue) Close() error {
+	if q.Log == nil {
+		return nil
+	}
+	return q.Log.Close()
+}
+
+func (q *Queue) appendAck(correlationID string) {
+	rec := make([]byte, 0, len(correlationID)+1)
+	rec = append(rec, recordAck)
+	rec = append(rec, correlationID...)
+	if err := q.Log.Append(rec); err != nil {
+		log.Printf("Job spool append error: %v", err)
+	}
+}
+
+// compact rewrites the spool with one enqueue record per pending request.
+func (q *Queue) compact() {
+	err := q.Log.Compact(func(emit func([]byte) error) error {
+		// Copied once the log is cut, so nothing spooled before the cut is
+		// left out.
+		q.mu.Lock()
+		pending := make([]model.PaymentRequest, 0, len(q.pending))
+		for _, req := range q.pending {
+			pending = append(pending, req)
+		}
+		q.mu.Unlock()
+		for _, req := range pending {
+			rec, err := encodeRequest(req)
+			if err != nil {
+				return err
+			}
+			if err := emit(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Job spool compaction error: %v", err)
+	}
+	q.mu.Lock()
+	q.compacting = false
+	q.mu.Unlock()
+}
+
+func encodeRequest(req model.PaymentRequest) ([]byte, error) {
+	body, err := easyjson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	rec := make([]byte, 0, len(body)+1)
+	rec = append(rec, recordEnqueue)
+	return append(rec, body...), nil
+}
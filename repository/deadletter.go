@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"log"
+	"rb2025-v3/model"
+	"rb2025-v3/wal"
+	"sort"
+	"sync"
+
+	"github.com/mailru/easyjson"
+)
+
+const (
+	recordDead   byte = 'D'
+	recordRemove byte = 'R'
+)
+
+// DeadLetterStore keeps the payments the worker gave up on until an operator
+// replays or discards them.
+type DeadLetterStore struct {
+	Log         *wal.Log
+	MaxSegments int
+	mu          sync.Mutex
+	letters     map[string]model.DeadLetter
+	compacting  bool
+}
+
+func NewDeadLetterStore(deadLog *wal.Log, maxSegments int) (*DeadLetterStore, error) {
+	s := &DeadLetterStore{
+		Log:         deadLog,
+		MaxSegments: maxSegments,
+		letters:     make(map[string]model.DeadLetter),
+	}
+	if deadLog == nil {
+		return s, nil
+	}
+	err := deadLog.Replay(func(rec []byte) error {
+		if len(rec) == 0 {
+			return errors.New("empty dead letter record")
+		}
+		switch rec[0] {
+		case recordDead:
+			var letter model.DeadLetter
+			if err := easyjson.Unmarshal(rec[1:], &letter); err != nil {
+				return err
+			}
+			s.letters[letter.CorrelationID] = letter
+		case recordRemove:
+			delete(s.letters, string(rec[1:]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	deadLog.Start()
+	return s, nil
+}
+
+// Add parks letter. It returns an error when the letter could not be written
+// to the dead letter log and so is not durable.
+func (s *DeadLetterStore) Add(letter model.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[letter.CorrelationID] = letter
+	if s.Log == nil {
+		return nil
+	}
+	rec, err := encodeDeadLetter(letter)
+	if err != nil {
+		return err
+	}
+	return s.append(rec)
+}
+
+func (s *DeadLetterStore) Get(correlationID string) (model.DeadLetter, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letter, ok := s.letters[correlationID]
+	return letter, ok
+}
+
+// List returns every dead letter, oldest first.
+func (s *DeadLetterStore) List() model.DeadLetters {
+	s.mu.Lock()
+	letters := make(model.DeadLetters, 0, len(s.letters))
+	for _, letter := range s.letters {
+		letters = append(letters, letter)
+	}
+	s.mu.Unlock()
+	sort.Slice(letters, func(i, j int) bool { return letters[i].DeadAt < letters[j].DeadAt })
+	return letters
+}
+
+func (s *DeadLetterStore) Remove(correlationID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.letters[correlationID]; !ok {
+		return false
+	}
+	delete(s.letters, correlationID)
+	if s.Log != nil {
+		rec := make([]byte, 0, len(correlationID)+1)
+		rec = append(rec, recordRemove)
+		if err := s.append(append(rec, correlationID...)); err != nil {
+			log.Printf("Dead letter log append error: %v", err)
+		}
+	}
+	return true
+}
+
+func (s *DeadLetterStore) Close() error {
+	if s.Log == nil {
+		return nil
+	}
+	return s.Log.Close()
+}
+
+func (s *DeadLetterStore) append(rec []byte) error {
+	if err := s.Log.Append(rec); err != nil {
+		return err
+	}
+	if !s.compacting && s.MaxSegments > 0 && s.Log.Segments() > s.MaxSegments {
+		s.compacting = true
+		go s.compact()
+	}
+	return nil
+}
+
+func (s *DeadLetterStore) compact() {
+	err := s.Log.Compact(func(emit func([]byte) error) error {
+		// Copied once the log is cut, so nothing added before the cut is
+		// left out.
+		s.mu.Lock()
+		letters := make([]model.DeadLetter, 0, len(s.letters))
+		for _, letter := range s.letters {
+			letters = append(letters, letter)
+		}
+		s.mu.Unlock()
+		for _, letter := range letters {
+			rec, err := encodeDeadLetter(letter)
+			if err != nil {
+				return err
+			}
+			if err := emit(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Dead letter log compaction error: %v", err)
+	}
+	s.mu.Lock()
+	s.compacting = false
+	s.mu.Unlock()
+}
+
+func encodeDeadLetter(letter model.DeadLetter) ([]byte, error) {
+	body, err := easyjson.Marshal(letter)
+	if err != nil {
+		return nil, err
+	}
+	rec := make([]byte, 0, len(body)+1)
+	rec = append(rec, recordDead)
+	return append(rec, body...), nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"rb2025-v3/model"
+	"time"
+)
+
+type intakeEntry struct {
+	Request     model.PaymentRequest
+	State       model.PaymentState
+	UpdatedAt   time.Time
+	Processor   int
+	RequestedAt time.Time
+	ReceivedAt  time.Time
+	Attempts    int
+}
+
+// Reserve registers req at intake. It returns false when the correlationId
+// was already seen, together with the state of that earlier payment.
+func (r *Repository) Reserve(req model.PaymentRequest) (bool, model.PaymentState) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	if _, ok := r.Payments.Load(req.CorrelationID); ok {
+		// Confirmed long enough ago for its intake entry to be evicted.
+		return false, model.StateConfirmed
+	}
+	if entry, ok := r.intake[req.CorrelationID]; ok {
+		return false, entry.State
+	}
+	now := time.Now()
+	r.intake[req.CorrelationID] = &intakeEntry{Request: req, State: model.StateReceived, UpdatedAt: now, ReceivedAt: now}
+	return true, model.StateReceived
+}
+
+// Release forgets a reservation whose request was never accepted.
+func (r *Repository) Release(correlationID string) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	if entry, ok := r.intake[correlationID]; ok && entry.State == model.StateReceived {
+		delete(r.intake, correlationID)
+	}
+}
+
+// Lookup returns the stored payment, or the intake state of one still in
+// progress, for correlationID.
+func (r *Repository) Lookup(correlationID string) (model.PaymentStatusResponse, bool) {
+	if value, ok := r.Payments.Load(correlationID); ok {
+		payment := value.(model.Payment)
+		return model.PaymentStatusResponse{
+			CorrelationID: payment.CorrelationID,
+			Amount:        payment.Amount,
+			Currency:      payment.Currency,
+			RequestedAt:   payment.RequestedAt.Format(time.RFC3339Nano),
+			Processor:     r.processorName(payment.Processor),
+			State:         model.StateConfirmed,
+		}, true
+	}
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok || entry.State == model.StateConfirmed {
+		return model.PaymentStatusResponse{}, false
+	}
+	status := model.PaymentStatusResponse{
+		CorrelationID: entry.Request.CorrelationID,
+		Amount:        entry.Request.Amount,
+		Currency:      entry.Request.Currency,
+		State:         entry.State,
+	}
+	if !entry.RequestedAt.IsZero() {
+		// Dispatched at least once; the processor is the latest one tried.
+		status.RequestedAt = entry.RequestedAt.Format(time.RFC3339Nano)
+		status.Processor = r.processorName(entry.Processor)
+	}
+	return status, true
+}
+
+// confirm moves the payment's intake entry to confirmed, creating it when the
+// payment was never tracked.
+func (r *Repository) confirm(payment model.Payment, at time.Time) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[payment.CorrelationID]
+	if !ok {
+		r.intake[payment.CorrelationID] = &intakeEntry{
+			Request:   model.PaymentRequest{CorrelationID: payment.CorrelationID, Amount: payment.Amount, Currency: payment.Currency},
+			State:     model.StateConfirmed,
+			UpdatedAt: at,
+		}
+		return nil
+	}
+	return r.transitionLocked(entry, model.StateConfirmed, at)
+}
+
+// Settle marks a tracked payment confirmed once it is found already stored,
+// e.g. when its job is replayed after the payment was recorded.
+func (r *Repository) Settle(correlationID string) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	if entry, ok := r.intake[correlationID]; ok && entry.State != model.StateConfirmed {
+		entry.State = model.StateConfirmed
+		entry.UpdatedAt = time.Now()
+	}
+}
+
+// evictIntake drops confirmed entries older than the retention window.
+// Entries still in progress are kept regardless of age.
+func (r *Repository) evictIntake(now time.Time) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	for id, entry := range r.intake {
+		if entry.State == model.StateConfirmed && now.Sub(entry.UpdatedAt) > r.Retention {
+			delete(r.intake, id)
+		}
+	}
+}
+
+func (r *Repository) startIntakeJanitor() {
+	interval := r.Retention / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	go func() {
+		for {
+			time.Sleep(interval)
+			r.evictIntake(time.Now())
+		}
+	}()
+}
+
+func (r *Repository) processorName(processor int) string {
+	if processor < 0 || processor >= len(r.ProcessorNames) {
+		return ""
+	}
+	return r.ProcessorNames[processor]
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"rb2025-v3/model"
+	"testing"
+	"time"
+)
+
+func TestPurgeKeepsPaymentsInProgress(t *testing.T) {
+	r, err := NewRepository(nil, time.Minute, false, []string{"default", "fallback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := model.PaymentRequest{CorrelationID: "done", Amount: 1000}
+	queued := model.PaymentRequest{CorrelationID: "queued", Amount: 1000}
+	unknown := model.PaymentRequest{CorrelationID: "unknown", Amount: 1000}
+	for _, req := range []model.PaymentRequest{done, queued, unknown} {
+		r.Reserve(req)
+	}
+	now := time.Now()
+	r.Dispatch(done.CorrelationID, 0, now)
+	if err := r.Add(model.Payment{CorrelationID: done.CorrelationID, Amount: done.Amount, RequestedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	r.Dispatch(unknown.CorrelationID, 0, now)
+	r.Transition(unknown.CorrelationID, model.StateUnknownOutcome)
+
+	r.PurgePayments()
+
+	if _, ok := r.State(done.CorrelationID); ok {
+		t.Error("confirmed payment still tracked after purge")
+	}
+	if state, _ := r.State(queued.CorrelationID); state != model.StateReceived {
+		t.Errorf("queued payment state = %q after purge, want %q", state, model.StateReceived)
+	}
+	if len(r.UnknownOutcomes()) != 1 {
+		t.Error("unknown outcome lost by purge")
+	}
+	if _, receivedAt, _ := r.Attempts(queued.CorrelationID); receivedAt.IsZero() {
+		t.Error("queued payment lost its receivedAt")
+	}
+}
@@ -1,48 +1,186 @@
 package repository
 
 import (
-	"math"
+	"errors"
+	"fmt"
+	"log"
 	"rb2025-v3/model"
+	"rb2025-v3/wal"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+const (
+	recordAdd   byte = 'A'
+	recordPurge byte = 'P'
 )
 
 type Repository struct {
-	Payments *sync.Map
+	Payments  *sync.Map
+	Log       *wal.Log
+	Retention time.Duration
+	// ProcessorNames names the processors by processor number.
+	ProcessorNames []string
+	// LogTransitions also logs the received -> dispatched -> confirmed
+	// transitions; every other transition is always logged.
+	LogTransitions bool
+	summaries      *summaryIndexes
+	compacting     atomic.Bool
+	intakeMu       sync.Mutex
+	intake         map[string]*intakeEntry
 }
 
-func NewRepository() *Repository {
+// NewRepository rebuilds the in-memory state from paymentLog, when given, and
+// keeps appending to it. A nil log keeps payments in memory only. Confirmed
+// correlationIds are remembered for deduplication during retention. The log
+// is only compacted after a purge, since nothing else makes records stale.
+func NewRepository(paymentLog *wal.Log, retention time.Duration, logTransitions bool, processorNames []string) (*Repository, error) {
 	payments := new(sync.Map)
-	return &Repository{Payments: payments}
+	r := &Repository{
+		Payments:       payments,
+		Log:            paymentLog,
+		Retention:      retention,
+		LogTransitions: logTransitions,
+		ProcessorNames: processorNames,
+		intake:         make(map[string]*intakeEntry),
+		summaries:      newSummaryIndexes(len(processorNames)),
+	}
+	if retention > 0 {
+		r.startIntakeJanitor()
+	}
+	if paymentLog == nil {
+		return r, nil
+	}
+	err := paymentLog.Replay(func(rec []byte) error {
+		if len(rec) == 0 {
+			return errors.New("empty payment record")
+		}
+		switch rec[0] {
+		case recordAdd:
+			var payment model.Payment
+			if err := easyjson.Unmarshal(rec[1:], &payment); err != nil {
+				return err
+			}
+			payments.Store(payment.CorrelationID, payment)
+		case recordPurge:
+			payments.Clear()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	payments.Range(func(key, value any) bool {
+		payment := value.(model.Payment)
+		r.confirm(payment, payment.RequestedAt)
+		r.summaries.add(payment.Processor, payment.Currency, payment.RequestedAt, payment.Amount)
+		return true
+	})
+	paymentLog.Start()
+	return r, nil
 }
 
-func (r *Repository) Add(payment model.Payment) {
+// ErrNotLogged means a payment was stored in memory but could not be written
+// to the payment log, so it is not durable yet.
+var ErrNotLogged = errors.New("payment not logged")
+
+// Add confirms payment and stores it. It fails without storing anything when
+// the payment cannot move to confirmed, e.g. because it already is, and with
+// ErrNotLogged when the payment log refused it.
+func (r *Repository) Add(payment model.Payment) error {
+	if err := r.confirm(payment, time.Now()); err != nil {
+		return err
+	}
 	r.Payments.Store(payment.CorrelationID, payment)
+	r.summaries.add(payment.Processor, payment.Currency, payment.RequestedAt, payment.Amount)
+	if r.Log == nil {
+		return nil
+	}
+	rec, err := encodePayment(payment)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotLogged, err)
+	}
+	if err := r.Log.Append(rec); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotLogged, err)
+	}
+	return nil
 }
 
 func (r *Repository) GetSummary(from, to time.Time) model.SummaryResponse {
-	var defaultSummary, fallbackSummary model.Summary
-	var defaultTotal, fallbackTotal float64
-	r.Payments.Range(func(key, value any) bool {
-		payment := value.(model.Payment)
-		if payment.RequestedAt.Before(from) || payment.RequestedAt.After(to) {
-			return true
-		}
-		switch payment.Processor {
-		case 0:
-			defaultTotal += payment.Amount
-			defaultSummary.TotalRequests += 1
-		case 1:
-			fallbackTotal += payment.Amount
-			fallbackSummary.TotalRequests += 1
-		}
-		return true
-	})
-	defaultSummary.TotalAmount = math.Round(float64(defaultTotal)*100) / 100
-	fallbackSummary.TotalAmount = math.Round(float64(fallbackTotal)*100) / 100
-	return model.SummaryResponse{Default: defaultSummary, Fallback: fallbackSummary}
+	response := model.SummaryResponse{
+		Processors: make(map[string]model.Summary, len(r.ProcessorNames)),
+		Currencies: make(map[string]map[model.Currency]model.Summary, len(r.ProcessorNames)),
+	}
+	for processor, name := range r.ProcessorNames {
+		summaries := r.summaries.sum(processor, from, to)
+		response.Processors[name] = summaries[model.DefaultCurrency]
+		response.Currencies[name] = summaries
+	}
+	response.Default = response.Processors["default"]
+	response.Fallback = response.Processors["fallback"]
+	return response
 }
 
 func (r *Repository) PurgePayments() {
 	r.Payments.Clear()
+	r.summaries.reset(len(r.ProcessorNames))
+	// Payments still in progress keep their entries, so they are sent and
+	// reconciled as usual.
+	r.intakeMu.Lock()
+	for id, entry := range r.intake {
+		if entry.State == model.StateConfirmed {
+			delete(r.intake, id)
+		}
+	}
+	r.intakeMu.Unlock()
+	if r.Log == nil {
+		return
+	}
+	if err := r.Log.Append([]byte{recordPurge}); err != nil {
+		log.Printf("Payment log append error: %v", err)
+	}
+	go r.compact()
+}
+
+func (r *Repository) Close() error {
+	if r.Log == nil {
+		return nil
+	}
+	return r.Log.Close()
+}
+
+// compact rewrites the log as one add record per stored payment.
+func (r *Repository) compact() {
+	if !r.compacting.CompareAndSwap(false, true) {
+		return
+	}
+	defer r.compacting.Store(false)
+	err := r.Log.Compact(func(emit func([]byte) error) error {
+		var err error
+		r.Payments.Range(func(key, value any) bool {
+			var rec []byte
+			rec, err = encodePayment(value.(model.Payment))
+			if err == nil {
+				err = emit(rec)
+			}
+			return err == nil
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("Payment log compaction error: %v", err)
+	}
+}
+
+func encodePayment(payment model.Payment) ([]byte, error) {
+	body, err := easyjson.Marshal(payment)
+	if err != nil {
+		return nil, err
+	}
+	rec := make([]byte, 0, len(body)+1)
+	rec = append(rec, recordAdd)
+	return append(rec, body...), nil
 }
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rb2025-v3/model"
+	"time"
+)
+
+// transitions lists the states a payment may move to from each state.
+var transitions = map[model.PaymentState][]model.PaymentState{
+	model.StateReceived:        {model.StateDispatched, model.StateDead},
+	model.StateDispatched:      {model.StateConfirmed, model.StateFailedRetryable, model.StateUnknownOutcome, model.StateDead},
+	model.StateFailedRetryable: {model.StateDispatched, model.StateDead},
+	model.StateUnknownOutcome:  {model.StateConfirmed, model.StateFailedRetryable, model.StateDead},
+	model.StateDead:            {model.StateReceived},
+	model.StateConfirmed:       {},
+}
+
+// ErrNotTracked means the payment has no intake entry, e.g. because it was
+// never reserved on this node.
+var ErrNotTracked = errors.New("not tracked")
+
+type TransitionError struct {
+	CorrelationID string
+	From          model.PaymentState
+	To            model.PaymentState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("payment %s: invalid transition %s -> %s", e.CorrelationID, e.From, e.To)
+}
+
+func canTransition(from, to model.PaymentState) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves a tracked payment to state to. Moving to confirmed goes
+// through Add, which also stores the payment.
+func (r *Repository) Transition(correlationID string, to model.PaymentState) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return fmt.Errorf("payment %s: %w", correlationID, ErrNotTracked)
+	}
+	return r.transitionLocked(entry, to, time.Now())
+}
+
+// Dispatch moves a payment to dispatched and remembers where and when it was
+// sent, so an ambiguous outcome can be reconciled later.
+func (r *Repository) Dispatch(correlationID string, processor int, requestedAt time.Time) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return fmt.Errorf("payment %s: %w", correlationID, ErrNotTracked)
+	}
+	if err := r.transitionLocked(entry, model.StateDispatched, time.Now()); err != nil {
+		return err
+	}
+	entry.Processor = processor
+	entry.RequestedAt = requestedAt
+	entry.Attempts++
+	return nil
+}
+
+// Hedge records that a dispatched payment is also being sent to processor,
+// so reconciliation starts there. It stays dispatched, and Add still lets
+// only one of the two attempts confirm it.
+func (r *Repository) Hedge(correlationID string, processor int) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return fmt.Errorf("payment %s: %w", correlationID, ErrNotTracked)
+	}
+	if entry.State != model.StateDispatched {
+		return &TransitionError{CorrelationID: correlationID, From: entry.State, To: model.StateDispatched}
+	}
+	entry.Processor = processor
+	entry.UpdatedAt = time.Now()
+	entry.Attempts++
+	if r.LogTransitions {
+		log.Printf("Payment %s: hedged to processor %d", correlationID, processor)
+	}
+	return nil
+}
+
+// Recover marks a payment replayed from the spool after a restart as having
+// an unknown outcome, since it may have been sent before the restart. The
+// reconciler then looks it up before it is sent again.
+func (r *Repository) Recover(correlationID string) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return fmt.Errorf("payment %s: %w", correlationID, ErrNotTracked)
+	}
+	if entry.State != model.StateReceived {
+		return &TransitionError{CorrelationID: correlationID, From: entry.State, To: model.StateUnknownOutcome}
+	}
+	entry.State = model.StateUnknownOutcome
+	entry.UpdatedAt = time.Now()
+	entry.Attempts++
+	return nil
+}
+
+// Attempts returns how many times a payment has been dispatched and when it
+// was received.
+func (r *Repository) Attempts(correlationID string) (int, time.Time, bool) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return entry.Attempts, entry.ReceivedAt, true
+}
+
+type Dispatched struct {
+	Request     model.PaymentRequest
+	Processor   int
+	RequestedAt time.Time
+	UpdatedAt   time.Time
+}
+
+// UnknownOutcomes returns the payments whose last dispatch has an unknown
+// outcome.
+func (r *Repository) UnknownOutcomes() []Dispatched {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	var unknown []Dispatched
+	for _, entry := range r.intake {
+		if entry.State != model.StateUnknownOutcome {
+			continue
+		}
+		unknown = append(unknown, Dispatched{
+			Request:     entry.Request,
+			Processor:   entry.Processor,
+			RequestedAt: entry.RequestedAt,
+			UpdatedAt:   entry.UpdatedAt,
+		})
+	}
+	return unknown
+}
+
+// State returns the current state of a tracked payment.
+func (r *Repository) State(correlationID string) (model.PaymentState, bool) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	entry, ok := r.intake[correlationID]
+	if !ok {
+		return "", false
+	}
+	return entry.State, true
+}
+
+func (r *Repository) transitionLocked(entry *intakeEntry, to model.PaymentState, at time.Time) error {
+	from := entry.State
+	if !canTransition(from, to) {
+		return &TransitionError{CorrelationID: entry.Request.CorrelationID, From: from, To: to}
+	}
+	entry.State = to
+	entry.UpdatedAt = at
+	if r.LogTransitions || !happyPath(from, to) {
+		log.Printf("Payment %s: %s -> %s", entry.Request.CorrelationID, from, to)
+	}
+	return nil
+}
+
+func happyPath(from, to model.PaymentState) bool {
+	return (from == model.StateReceived && to == model.StateDispatched) ||
+		(from == model.StateDispatched && to == model.StateConfirmed)
+}
+
+// Revive moves a dead payment back to received with a fresh retry budget,
+// optionally replacing its request.
+func (r *Repository) Revive(req model.PaymentRequest) error {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	now := time.Now()
+	entry, ok := r.intake[req.CorrelationID]
+	if !ok {
+		// Evicted or never tracked on this node, e.g. after a restart.
+		r.intake[req.CorrelationID] = &intakeEntry{Request: req, State: model.StateReceived, UpdatedAt: now, ReceivedAt: now}
+		return nil
+	}
+	if err := r.transitionLocked(entry, model.StateReceived, now); err != nil {
+		return err
+	}
+	entry.Request = req
+	entry.ReceivedAt = now
+	entry.Attempts = 0
+	return nil
+}
+
+// Forget drops a dead payment so its correlationId can be submitted again.
+func (r *Repository) Forget(correlationID string) {
+	r.intakeMu.Lock()
+	defer r.intakeMu.Unlock()
+	if entry, ok := r.intake[correlationID]; ok && entry.State == model.StateDead {
+		delete(r.intake, correlationID)
+	}
+}
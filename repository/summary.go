@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"rb2025-v3/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// summaryBucketWidth is the time span of one summary index bucket, in
+// seconds.
+const summaryBucketWidth = 1
+
+type summaryEntry struct {
+	at     time.Time
+	amount model.Money
+}
+
+type summaryBucket struct {
+	key int64
+	// requests and amount total this bucket and every earlier one.
+	requests int
+	amount   model.Money
+	// entries lets a bucket cut by the window's edge be counted exactly.
+	entries []summaryEntry
+}
+
+// summaryIndex keeps running totals of one processor's payments per time
+// bucket, so a summary costs two binary searches and the entries of the two
+// buckets at the window's edges, however many payments it covers.
+type summaryIndex struct {
+	buckets []*summaryBucket
+}
+
+func newSummaryIndex() *summaryIndex {
+	return &summaryIndex{}
+}
+
+func bucketKey(t time.Time) int64 {
+	sec := t.Unix()
+	if sec < 0 && sec%summaryBucketWidth != 0 {
+		return sec/summaryBucketWidth - 1
+	}
+	return sec / summaryBucketWidth
+}
+
+func (idx *summaryIndex) add(at time.Time, amount model.Money) {
+	key := bucketKey(at)
+	// Payments arrive almost in order, so this is nearly always the last
+	// bucket or a new one after it, and the totals after it need no update.
+	i := len(idx.buckets)
+	for i > 0 && idx.buckets[i-1].key > key {
+		i--
+	}
+	if i == 0 || idx.buckets[i-1].key != key {
+		b := &summaryBucket{key: key}
+		if i > 0 {
+			b.requests = idx.buckets[i-1].requests
+			b.amount = idx.buckets[i-1].amount
+		}
+		idx.buckets = append(idx.buckets, nil)
+		copy(idx.buckets[i+1:], idx.buckets[i:])
+		idx.buckets[i] = b
+		i++
+	}
+	idx.buckets[i-1].entries = append(idx.buckets[i-1].entries, summaryEntry{at: at, amount: amount})
+	for _, b := range idx.buckets[i-1:] {
+		b.requests++
+		b.amount += amount
+	}
+}
+
+// sum totals the payments requested within [from, to].
+func (idx *summaryIndex) sum(from, to time.Time) (int, model.Money) {
+	fromKey, toKey := bucketKey(from), bucketKey(to)
+	lo := sort.Search(len(idx.buckets), func(i int) bool { return idx.buckets[i].key >= fromKey })
+	hi := sort.Search(len(idx.buckets), func(i int) bool { return idx.buckets[i].key > toKey })
+	if lo >= hi {
+		return 0, 0
+	}
+	requests, amount := idx.buckets[hi-1].requests, idx.buckets[hi-1].amount
+	if lo > 0 {
+		requests -= idx.buckets[lo-1].requests
+		amount -= idx.buckets[lo-1].amount
+	}
+	// Take out what the edge buckets hold outside the window.
+	edges := []*summaryBucket{idx.buckets[lo]}
+	if hi-1 != lo {
+		edges = append(edges, idx.buckets[hi-1])
+	}
+	for _, b := range edges {
+		if b.key != fromKey && b.key != toKey {
+			continue
+		}
+		for _, entry := range b.entries {
+			if entry.at.Before(from) || entry.at.After(to) {
+				requests--
+				amount -= entry.amount
+			}
+		}
+	}
+	return requests, amount
+}
+
+// summaryIndexes holds a summaryIndex per processor and currency.
+type summaryIndexes struct {
+	mu         sync.RWMutex
+	processors []map[model.Currency]*summaryIndex
+}
+
+func newSummaryIndexes(processors int) *summaryIndexes {
+	s := &summaryIndexes{}
+	s.reset(processors)
+	return s
+}
+
+func (s *summaryIndexes) reset(processors int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processors = make([]map[model.Currency]*summaryIndex, processors)
+	for i := range s.processors {
+		s.processors[i] = make(map[model.Currency]*summaryIndex)
+	}
+}
+
+func (s *summaryIndexes) add(processor int, currency model.Currency, at time.Time, amount model.Money) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if processor < 0 || processor >= len(s.processors) {
+		return
+	}
+	currency = currency.OrDefault()
+	idx, ok := s.processors[processor][currency]
+	if !ok {
+		idx = newSummaryIndex()
+		s.processors[processor][currency] = idx
+	}
+	idx.add(at, amount)
+}
+
+// sum returns the processor's totals within [from, to] per currency.
+func (s *summaryIndexes) sum(processor int, from, to time.Time) map[model.Currency]model.Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summaries := make(map[model.Currency]model.Summary, len(s.processors[processor]))
+	for currency, idx := range s.processors[processor] {
+		requests, amount := idx.sum(from, to)
+		if requests > 0 {
+			summaries[currency] = model.Summary{TotalRequests: requests, TotalAmount: amount}
+		}
+	}
+	return summaries
+}
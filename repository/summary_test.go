@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+	"math/rand"
+	"rb2025-v3/model"
+	"testing"
+	"time"
+)
+
+func TestSummaryIndexMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	idx := newSummaryIndex()
+	var entries []summaryEntry
+	for i := 0; i < 5000; i++ {
+		// Mostly in order, with some payments recorded late.
+		offset := time.Duration(i) * 7 * time.Millisecond
+		if rng.Intn(10) == 0 {
+			offset -= time.Duration(rng.Intn(5000)) * time.Millisecond
+		}
+		entry := summaryEntry{at: base.Add(offset), amount: model.Money(rng.Intn(100000) + 1)}
+		entries = append(entries, entry)
+		idx.add(entry.at, entry.amount)
+	}
+	for i := 0; i < 500; i++ {
+		from := base.Add(time.Duration(rng.Intn(40000)-2000) * time.Millisecond)
+		to := from.Add(time.Duration(rng.Intn(20000)) * time.Millisecond)
+		var wantRequests int
+		var wantAmount model.Money
+		for _, entry := range entries {
+			if !entry.at.Before(from) && !entry.at.After(to) {
+				wantRequests++
+				wantAmount += entry.amount
+			}
+		}
+		requests, amount := idx.sum(from, to)
+		if requests != wantRequests || amount != wantAmount {
+			t.Fatalf("sum(%v, %v) = %d, %s; want %d, %s", from, to, requests, amount, wantRequests, wantAmount)
+		}
+	}
+}
+
+func BenchmarkGetSummary(b *testing.B) {
+	for _, payments := range []int{1_000_000, 4_000_000} {
+		b.Run(fmt.Sprintf("payments=%d", payments), func(b *testing.B) {
+			r, err := NewRepository(nil, time.Minute, false, []string{"default", "fallback"})
+			if err != nil {
+				b.Fatal(err)
+			}
+			// A payment every millisecond, spread over both processors.
+			base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+			for i := 0; i < payments; i++ {
+				err := r.Add(model.Payment{
+					CorrelationID: fmt.Sprintf("payment-%d", i),
+					Amount:        19900,
+					Processor:     i % 2,
+					RequestedAt:   base.Add(time.Duration(i) * time.Millisecond),
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			span := time.Duration(payments) * time.Millisecond
+			from := base.Add(span / 10).Add(123 * time.Millisecond)
+			to := base.Add(span * 9 / 10).Add(456 * time.Millisecond)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.GetSummary(from, to)
+			}
+		})
+	}
+}
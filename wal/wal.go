@@ -0,0 +1,480 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentExt = ".log"
+	headerSize = 8
+)
+
+var (
+	ErrClosed         = errors.New("wal: log closed")
+	ErrRecordTooLarge = errors.New("wal: record larger than a segment")
+)
+
+type Options struct {
+	SegmentSize  int64
+	SyncInterval time.Duration
+}
+
+// Log is an append-only record log split into numbered segment files.
+// Appends are buffered and fsynced in batches every SyncInterval.
+type Log struct {
+	Dir          string
+	SegmentSize  int64
+	SyncInterval time.Duration
+	// Upstream logs are synced before any of this log's records reach its
+	// file, so a record written here after one written there is never
+	// durable without it.
+	Upstream []*Log
+
+	mu       sync.Mutex
+	segments []int
+	file     *os.File
+	buf      *bufio.Writer
+	size     int64
+	dirty    bool
+	closed   bool
+	stop     chan struct{}
+	stopped  chan struct{}
+	// appended counts records appended and synced those made durable;
+	// failed is appended as of the last failed sync, which failed with
+	// syncErr. Commit waits on synced.
+	appended uint64
+	synced   uint64
+	failed   uint64
+	syncErr  error
+	commit   *sync.Cond
+}
+
+func Open(dir string, opts Options) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 16 << 20
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = 10 * time.Millisecond
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{
+		Dir:          dir,
+		SegmentSize:  opts.SegmentSize,
+		SyncInterval: opts.SyncInterval,
+		segments:     segments,
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	l.commit = sync.NewCond(&l.mu)
+	return l, nil
+}
+
+// Replay calls fn for every record in order. A torn or corrupt tail on the
+// last segment is truncated; corruption anywhere else is an error. Replay
+// must be called before the first Append.
+func (l *Log) Replay(fn func(rec []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, seq := range l.segments {
+		last := i == len(l.segments)-1
+		if err := l.replaySegment(seq, last, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Log) replaySegment(seq int, last bool, fn func(rec []byte) error) error {
+	path := l.segmentPath(seq)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	var offset int64
+	header := make([]byte, headerSize)
+	for {
+		rec, err := readRecord(r, header, info.Size()-offset-headerSize)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if !last {
+				return fmt.Errorf("wal: segment %s: %w", path, err)
+			}
+			log.Printf("WAL: truncating %s at offset %d: %v", path, offset, err)
+			return os.Truncate(path, offset)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+		offset += int64(headerSize + len(rec))
+	}
+}
+
+// readRecord reads the next record, which cannot be longer than max bytes
+// without running past the end of the segment.
+func readRecord(r *bufio.Reader, header []byte, max int64) ([]byte, error) {
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	n := binary.LittleEndian.Uint32(header[0:4])
+	sum := binary.LittleEndian.Uint32(header[4:8])
+	if int64(n) > max {
+		// A torn or corrupt length; do not allocate it.
+		return nil, io.ErrUnexpectedEOF
+	}
+	rec := make([]byte, n)
+	if _, err := io.ReadFull(r, rec); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(rec) != sum {
+		return nil, errors.New("checksum mismatch")
+	}
+	return rec, nil
+}
+
+// Append buffers a record on the active segment, rotating to a new segment
+// when the active one reaches SegmentSize. A record that cannot fit in a
+// segment is refused. The record becomes durable on the next background sync.
+func (l *Log) Append(rec []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if int64(headerSize+len(rec)) > l.SegmentSize {
+		return ErrRecordTooLarge
+	}
+	if l.file == nil {
+		if err := l.openActive(); err != nil {
+			return err
+		}
+	}
+	if l.size > 0 && l.size+int64(headerSize+len(rec)) > l.SegmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	if l.buf.Available() < headerSize+len(rec) {
+		// The write below would flush the buffer to the file.
+		if err := l.syncUpstream(); err != nil {
+			return err
+		}
+	}
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(rec)))
+	binary.LittleEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(rec))
+	if _, err := l.buf.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := l.buf.Write(rec); err != nil {
+		return err
+	}
+	l.size += int64(headerSize + len(rec))
+	l.dirty = true
+	l.appended++
+	return nil
+}
+
+// Commit waits until every record appended so far is durable. It does not
+// sync itself but waits for the next background sync, so concurrent callers
+// share one fsync.
+func (l *Log) Commit() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	target := l.appended
+	for l.synced < target {
+		if l.failed >= target {
+			return l.syncErr
+		}
+		if l.closed {
+			return ErrClosed
+		}
+		l.commit.Wait()
+	}
+	return nil
+}
+
+// Segments returns the number of segment files currently on disk.
+func (l *Log) Segments() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.segments)
+}
+
+// Compact replaces every existing segment with a single segment holding the
+// records emitted by snapshot. Appends carry on into a new segment while the
+// snapshot is written, so the snapshot may already reflect some of them; they
+// are replayed after it. That is only correct for records that set or delete
+// a key, which can be applied twice.
+func (l *Log) Compact(snapshot func(emit func(rec []byte) error) error) error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if err := l.closeActive(); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	// Reserve the snapshot's place before anything appended from now on.
+	seq := l.nextSeq()
+	l.segments = append(l.segments, seq)
+	l.mu.Unlock()
+
+	path := l.segmentPath(seq)
+	tmp := path + ".tmp"
+	err := writeSegment(tmp, snapshot)
+	if err == nil {
+		// The snapshot may leave out records that depend on upstream ones.
+		err = l.syncUpstream()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		l.segments = removeSegment(l.segments, seq)
+		return err
+	}
+	kept := l.segments[:0]
+	for _, old := range l.segments {
+		if old >= seq {
+			kept = append(kept, old)
+			continue
+		}
+		if err := os.Remove(l.segmentPath(old)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	l.segments = kept
+	return syncDir(l.Dir)
+}
+
+func writeSegment(path string, snapshot func(emit func(rec []byte) error) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var header [headerSize]byte
+	err = snapshot(func(rec []byte) error {
+		binary.LittleEndian.PutUint32(header[0:4], uint32(len(rec)))
+		binary.LittleEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(rec))
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		_, err := w.Write(rec)
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func removeSegment(segments []int, seq int) []int {
+	for i, s := range segments {
+		if s == seq {
+			return append(segments[:i], segments[i+1:]...)
+		}
+	}
+	return segments
+}
+
+// Start launches the background flusher that fsyncs buffered appends.
+func (l *Log) Start() {
+	go func() {
+		defer close(l.stopped)
+		ticker := time.NewTicker(l.SyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := l.Sync(); err != nil {
+					log.Printf("WAL sync error: %v", err)
+				}
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Sync flushes buffered appends and fsyncs the active segment.
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.syncLocked()
+}
+
+func (l *Log) syncLocked() error {
+	if !l.dirty || l.file == nil {
+		l.synced = l.appended
+		l.commit.Broadcast()
+		return nil
+	}
+	if err := l.flushLocked(); err != nil {
+		l.failed, l.syncErr = l.appended, err
+		l.commit.Broadcast()
+		return err
+	}
+	l.dirty = false
+	l.synced = l.appended
+	l.commit.Broadcast()
+	return nil
+}
+
+func (l *Log) flushLocked() error {
+	if err := l.syncUpstream(); err != nil {
+		return err
+	}
+	if err := l.buf.Flush(); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *Log) syncUpstream() error {
+	for _, upstream := range l.Upstream {
+		if err := upstream.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	err := l.closeActive()
+	l.commit.Broadcast()
+	l.mu.Unlock()
+	close(l.stop)
+	return err
+}
+
+func (l *Log) openActive() error {
+	seq := l.nextSeq()
+	f, err := os.OpenFile(l.segmentPath(seq), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// Without the directory entry, records synced into the file are lost.
+	if err := syncDir(l.Dir); err != nil {
+		f.Close()
+		return err
+	}
+	l.segments = append(l.segments, seq)
+	l.file = f
+	l.buf = bufio.NewWriterSize(f, 64<<10)
+	l.size = 0
+	return nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.closeActive(); err != nil {
+		return err
+	}
+	return l.openActive()
+}
+
+func (l *Log) closeActive() error {
+	if l.file == nil {
+		return nil
+	}
+	err := l.syncLocked()
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	l.buf = nil
+	l.size = 0
+	return err
+}
+
+func (l *Log) nextSeq() int {
+	if len(l.segments) == 0 {
+		return 1
+	}
+	return l.segments[len(l.segments)-1] + 1
+}
+
+func (l *Log) segmentPath(seq int) string {
+	return filepath.Join(l.Dir, fmt.Sprintf("%016d%s", seq, segmentExt))
+}
+
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []int
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, segmentExt+".tmp") {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(name, segmentExt))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, seq)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,205 @@
+package wal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openLog(t *testing.T, dir string, opts Options) *Log {
+	t.Helper()
+	l, err := Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func replayAll(t *testing.T, l *Log) [][]byte {
+	t.Helper()
+	var recs [][]byte
+	err := l.Replay(func(rec []byte) error {
+		recs = append(recs, bytes.Clone(rec))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	return recs
+}
+
+func TestCommitWaitsForBackgroundSync(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, Options{SyncInterval: 50 * time.Millisecond})
+	l.Start()
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Append([]byte(fmt.Sprintf("record-%d", i))); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := l.Commit(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Everything committed is in the file without closing the log.
+	if recs := replayAll(t, openLog(t, dir, Options{})); len(recs) != 20 {
+		t.Fatalf("replayed %d records after Commit, want 20", len(recs))
+	}
+}
+
+func TestCommitAfterCloseFails(t *testing.T) {
+	l := openLog(t, t.TempDir(), Options{})
+	l.Close()
+	if err := l.Commit(); err != nil {
+		t.Fatalf("Commit with nothing appended = %v, want nil", err)
+	}
+	if err := l.Append([]byte("late")); err != ErrClosed {
+		t.Fatalf("Append after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestCompactKeepsAppendsMadeDuringSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, Options{SegmentSize: 64})
+	for i := 0; i < 10; i++ {
+		if err := l.Append([]byte(fmt.Sprintf("old-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.Segments() < 2 {
+		t.Fatalf("expected rotation, have %d segment(s)", l.Segments())
+	}
+	err := l.Compact(func(emit func([]byte) error) error {
+		// Appends are not blocked while the snapshot is written.
+		if err := l.Append([]byte("during")); err != nil {
+			return err
+		}
+		return emit([]byte("snapshot"))
+	})
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if err := l.Append([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	recs := replayAll(t, openLog(t, dir, Options{SegmentSize: 64}))
+	want := []string{"snapshot", "during", "after"}
+	if len(recs) != len(want) {
+		t.Fatalf("replayed %q, want %q", recs, want)
+	}
+	for i := range want {
+		if string(recs[i]) != want[i] {
+			t.Fatalf("replayed %q, want %q", recs, want)
+		}
+	}
+}
+
+func TestReplayTruncatesTornTail(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, Options{})
+	l.Append([]byte("first"))
+	l.Append([]byte("second"))
+	l.Close()
+
+	path := l.segmentPath(1)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intact := info.Size()
+	for name, tail := range map[string][]byte{
+		"short header": {3, 0},
+		"short record": {10, 0, 0, 0, 0, 0, 0, 0, 'x'},
+		// A length near 4 GiB must not be allocated.
+		"huge length":  {0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 'x'},
+		"bad checksum": {1, 0, 0, 0, 0, 0, 0, 0, 'x'},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write(tail)
+			f.Close()
+
+			recs := replayAll(t, openLog(t, dir, Options{}))
+			if len(recs) != 2 || string(recs[0]) != "first" || string(recs[1]) != "second" {
+				t.Fatalf("replayed %q, want first and second", recs)
+			}
+			if info, _ := os.Stat(path); info.Size() != intact {
+				t.Fatalf("segment is %d bytes after replay, want %d", info.Size(), intact)
+			}
+		})
+	}
+}
+
+func TestReplayRejectsCorruptionBeforeLastSegment(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, Options{SegmentSize: 32})
+	for i := 0; i < 4; i++ {
+		l.Append([]byte(fmt.Sprintf("record-%d", i)))
+	}
+	l.Close()
+	if l.Segments() < 2 {
+		t.Fatalf("expected rotation, have %d segment(s)", l.Segments())
+	}
+	f, err := os.OpenFile(l.segmentPath(1), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0xff, 0xff})
+	f.Close()
+
+	err = openLog(t, dir, Options{SegmentSize: 32}).Replay(func([]byte) error { return nil })
+	if err == nil {
+		t.Fatal("Replay accepted a corrupt segment before the last one")
+	}
+}
+
+func TestAppendRotatesSegments(t *testing.T) {
+	dir := t.TempDir()
+	l := openLog(t, dir, Options{SegmentSize: 32})
+	if err := l.Append(make([]byte, 32)); err != ErrRecordTooLarge {
+		t.Fatalf("Append of an oversized record = %v, want ErrRecordTooLarge", err)
+	}
+	var want []string
+	for i := 0; i < 10; i++ {
+		rec := fmt.Sprintf("record-%d", i)
+		want = append(want, rec)
+		if err := l.Append([]byte(rec)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+	if l.Segments() != 5 {
+		t.Fatalf("%d segments, want 5 with two records each", l.Segments())
+	}
+	for _, seq := range l.segments {
+		if info, err := os.Stat(l.segmentPath(seq)); err != nil || info.Size() > 32 {
+			t.Fatalf("segment %d: %v, size over 32", seq, err)
+		}
+	}
+	recs := replayAll(t, openLog(t, dir, Options{SegmentSize: 32}))
+	if len(recs) != len(want) {
+		t.Fatalf("replayed %d records, want %d", len(recs), len(want))
+	}
+	for i := range want {
+		if string(recs[i]) != want[i] {
+			t.Fatalf("record %d = %q, want %q", i, recs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"time"
+)
+
+// DeadlinePolicy bounds each payment attempt by a multiple of the
+// processor's polled minimum response time, kept between Min and Max. A zero
+// Multiplier leaves only the client's own timeout.
+type DeadlinePolicy struct {
+	Multiplier float64
+	Min        time.Duration
+	Max        time.Duration
+}
+
+func (p DeadlinePolicy) timeout(minResponse time.Duration) time.Duration {
+	if p.Multiplier <= 0 {
+		return 0
+	}
+	timeout := time.Duration(p.Multiplier * float64(minResponse))
+	if timeout < p.Min {
+		timeout = p.Min
+	}
+	if p.Max > 0 && timeout > p.Max {
+		timeout = p.Max
+	}
+	return timeout
+}
+
+// send waits for a slot under processor's concurrency limit and posts the
+// payment within the attempt deadline, which starts once the slot is taken,
+// and within ctx.
+func (w *Worker) send(ctx context.Context, processor int, event model.PaymentEvent) client.Outcome {
+	limiter := w.Client.Limiters[processor]
+	if err := limiter.Acquire(ctx); err != nil {
+		// Stopping or out of time; the payment was not sent.
+		return client.OutcomeRetryable
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.attemptTimeout(processor))
+	defer cancel()
+	start := time.Now()
+	outcome := client.Classify(w.Client.PostJSON(ctx, processor, event))
+	if outcome == client.OutcomeOpen {
+		limiter.Cancel()
+	} else {
+		limiter.Release(time.Since(start), outcome)
+	}
+	return outcome
+}
+
+// attemptTimeout is the longest a single post to processor may take.
+func (w *Worker) attemptTimeout(processor int) time.Duration {
+	minResponse := Snapshot{Health: w.routing.Load().Health}.MinResponse(processor)
+	timeout := w.Deadline.timeout(minResponse)
+	if timeout <= 0 {
+		// A deadline lets the client send without watching ctx.
+		timeout = w.Client.Timeout
+	}
+	return timeout
+}
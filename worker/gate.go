@@ -0,0 +1,50 @@
+package worker
+
+import "sync"
+
+// gate holds worker goroutines back while it is closed.
+type gate struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	closed  bool
+	stopped bool
+}
+
+func newGate() *gate {
+	g := &gate{}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// Wait blocks while the gate is closed. It returns false once the gate is
+// stopped.
+func (g *gate) Wait() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for g.closed && !g.stopped {
+		g.cond.Wait()
+	}
+	return !g.stopped
+}
+
+// Set opens or closes the gate and reports whether that changed it.
+func (g *gate) Set(closed bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed == closed {
+		return false
+	}
+	g.closed = closed
+	if !closed {
+		g.cond.Broadcast()
+	}
+	return true
+}
+
+// Stop releases every waiter for good.
+func (g *gate) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = true
+	g.cond.Broadcast()
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitAll(t *testing.T, n int, results <-chan bool, want bool) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for i := 0; i < n; i++ {
+		select {
+		case got := <-results:
+			if got != want {
+				t.Fatalf("Wait() = %v, want %v", got, want)
+			}
+		case <-timeout:
+			t.Fatalf("%d of %d waiters still blocked", n-i, n)
+		}
+	}
+}
+
+func TestGateHoldsWaitersWhileClosed(t *testing.T) {
+	g := newGate()
+	if !g.Set(true) {
+		t.Fatal("Set(true) on an open gate reported no change")
+	}
+	if g.Set(true) {
+		t.Fatal("Set(true) on a closed gate reported a change")
+	}
+	results := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		go func() { results <- g.Wait() }()
+	}
+	select {
+	case <-results:
+		t.Fatal("Wait returned while the gate was closed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	g.Set(false)
+	waitAll(t, 10, results, true)
+}
+
+func TestGateStopReleasesWaiters(t *testing.T) {
+	g := newGate()
+	g.Set(true)
+	results := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		go func() { results <- g.Wait() }()
+	}
+	g.Stop()
+	waitAll(t, 10, results, false)
+	if g.Wait() {
+		t.Fatal("Wait after Stop returned true")
+	}
+}
+
+func TestGateConcurrentSet(t *testing.T) {
+	g := newGate()
+	var waiters, setters sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		waiters.Add(1)
+		go func() {
+			defer waiters.Done()
+			for g.Wait() {
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		setters.Add(1)
+		go func(closed bool) {
+			defer setters.Done()
+			for j := 0; j < 1000; j++ {
+				g.Set(closed)
+				closed = !closed
+			}
+		}(i%2 == 0)
+	}
+	setters.Wait()
+	g.Stop()
+	done := make(chan struct{})
+	go func() {
+		waiters.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiters still blocked after Stop")
+	}
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"time"
+)
+
+// HedgePolicy moves a payment to a second processor when the first has not
+// answered within the Percentile latency it has shown recently. A zero
+// Percentile disables hedging.
+//
+// The two attempts never overlap: the first is cut off at the hedge delay,
+// and when it may have reached the processor the payment is only hedged
+// after Settle has passed and a lookup shows the first processor has not
+// recorded it.
+type HedgePolicy struct {
+	Percentile float64
+	// MinDelay is the shortest wait before hedging, and the wait used while
+	// there are no latency samples yet.
+	MinDelay time.Duration
+	// Settle is how long a cut-off attempt is given to land before the
+	// lookup. The wait is never shorter than the attempt deadline the
+	// processor could still be working within.
+	Settle time.Duration
+}
+
+func (p HedgePolicy) delay(stats client.Stats) time.Duration {
+	if p.Percentile <= 0 {
+		return 0
+	}
+	return max(stats.Percentile(p.Percentile), p.MinDelay)
+}
+
+// post sends the payment to processor, hedging to the hedge processor when
+// the policy allows it, and returns the processor that settled it.
+func (w *Worker) post(processor, hedge int, event model.PaymentEvent) (int, client.Outcome) {
+	delay := w.Hedge.delay(w.Client.Stats(processor))
+	if hedge < 0 || delay <= 0 {
+		return processor, w.send(w.ctx, processor, event)
+	}
+
+	ctx, cancel := context.WithTimeout(w.ctx, delay)
+	outcome := w.send(ctx, processor, event)
+	cancel()
+	switch outcome {
+	case client.OutcomeSuccess, client.OutcomeRejected:
+		return processor, outcome
+	case client.OutcomeUnknown:
+		err := w.settle(processor, event.CorrelationID)
+		if err == nil {
+			return processor, client.OutcomeSuccess
+		}
+		if err != client.ErrNotFound {
+			return processor, outcome
+		}
+	}
+
+	if w.ctx.Err() != nil {
+		return processor, outcome
+	}
+	if err := w.Repository.Hedge(event.CorrelationID, hedge); err != nil {
+		log.Printf("Hedge error: %v", err)
+		return processor, outcome
+	}
+	return hedge, w.send(w.ctx, hedge, event)
+}
+
+// settle waits for a cut-off attempt to land and then looks the payment up on
+// processor. Only client.ErrNotFound makes it safe to send it elsewhere.
+func (w *Worker) settle(processor int, correlationID string) error {
+	timer := time.NewTimer(max(w.Hedge.Settle, w.attemptTimeout(processor)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+	_, err := w.Client.LookupPayment(w.ctx, processor, correlationID)
+	return err
+}
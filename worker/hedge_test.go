@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"rb2025-v3/queue"
+	"rb2025-v3/repository"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ledger is a processor that records a payment late after accepting it,
+// whether or not the client is still waiting for the answer.
+type ledger struct {
+	late time.Duration
+
+	mu       sync.Mutex
+	payments map[string]bool
+}
+
+func (l *ledger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		id := strings.TrimPrefix(r.URL.Path, "/payments/")
+		l.mu.Lock()
+		ok := l.payments[id]
+		l.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"correlationId":%q,"amount":1,"requestedAt":"2025-07-15T12:00:00.000Z"}`, id)
+		return
+	}
+	var req model.PaymentRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	time.Sleep(l.late)
+	l.mu.Lock()
+	l.payments[req.CorrelationID] = true
+	l.mu.Unlock()
+}
+
+func (l *ledger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.payments)
+}
+
+func TestHedgeNeverChargesTwiceWhenPrimaryAnswersLate(t *testing.T) {
+	primary := &ledger{late: 200 * time.Millisecond, payments: map[string]bool{}}
+	fallback := &ledger{payments: map[string]bool{}}
+	primaryServer := httptest.NewServer(primary)
+	t.Cleanup(primaryServer.Close)
+	fallbackServer := httptest.NewServer(fallback)
+	t.Cleanup(fallbackServer.Close)
+
+	processors := []client.Processor{{Name: "default", Url: primaryServer.URL}, {Name: "fallback", Url: fallbackServer.URL}}
+	c, err := client.NewClient(processors, primaryServer.URL, time.Second, client.BreakerSettings{}, client.LimiterSettings{Initial: 100, Max: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Timeout = 500 * time.Millisecond
+	q, err := queue.NewQueue(10, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := repository.NewRepository(nil, time.Minute, false, c.ProcessorNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl, err := repository.NewDeadLetterStore(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorker(q, r, dl, c, PriorityFirst{}, 1, 0, RetryPolicy{MaxAttempts: 1}, 100, PassivePolicy{MinSamples: 1000})
+	w.routing.Store(&routing{Health: healthyHealth})
+	// The hedge fires long before the primary answers, and Settle alone
+	// would look the payment up before it lands.
+	w.Hedge = HedgePolicy{Percentile: 0.99, MinDelay: 20 * time.Millisecond, Settle: 10 * time.Millisecond}
+
+	req := model.PaymentRequest{CorrelationID: "late-payment", Amount: 1000}
+	r.Reserve(req)
+	if err := r.Dispatch(req.CorrelationID, 0, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	processor, outcome := w.post(0, 1, model.PaymentEvent{CorrelationID: req.CorrelationID, Amount: req.Amount, RequestedAt: time.Now().Format(time.RFC3339Nano)})
+
+	if charges := primary.count() + fallback.count(); charges != 1 {
+		t.Fatalf("payment charged %d times, want once", charges)
+	}
+	if processor != 0 || outcome != client.OutcomeSuccess {
+		t.Fatalf("post settled on processor %d with outcome %v, want the primary's success", processor, outcome)
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"log"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"rb2025-v3/repository"
+	"time"
+)
+
+func (w *Worker) reconciler() {
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-time.After(w.ReconcileEvery):
+		}
+		now := time.Now()
+		for _, dispatched := range w.Repository.UnknownOutcomes() {
+			// Give the processor a full interval to finish a slow charge
+			// before asking about it.
+			if now.Sub(dispatched.UpdatedAt) < w.ReconcileEvery {
+				continue
+			}
+			w.reconcile(dispatched)
+		}
+	}
+}
+
+// reconcile settles an unknown outcome by looking the payment up on every
+// processor, starting with the one it was sent to. It is confirmed where it
+// is found and retried only when every processor answers that it is absent.
+func (w *Worker) reconcile(dispatched repository.Dispatched) {
+	correlationID := dispatched.Request.CorrelationID
+	order := make([]int, 0, len(w.Client.Processors))
+	order = append(order, dispatched.Processor)
+	for processor := range w.Client.Processors {
+		if processor != dispatched.Processor {
+			order = append(order, processor)
+		}
+	}
+
+	for _, processor := range order {
+		found, err := w.Client.LookupPayment(w.ctx, processor, correlationID)
+		if err == client.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			log.Printf("Reconcile lookup error for %s: %v", correlationID, err)
+			return
+		}
+		requestedAt := dispatched.RequestedAt
+		if requestedAt.IsZero() {
+			// Recovered after a restart; only the processor knows when.
+			requestedAt, err = time.Parse(time.RFC3339Nano, found.RequestedAt)
+			if err != nil {
+				log.Printf("Reconcile lookup error for %s: %v", correlationID, err)
+				return
+			}
+			requestedAt = requestedAt.UTC()
+		}
+		payment := model.Payment{
+			CorrelationID: correlationID,
+			Amount:        dispatched.Request.Amount,
+			Currency:      dispatched.Request.Currency,
+			Processor:     processor,
+			RequestedAt:   requestedAt,
+		}
+		if err := w.Repository.Add(payment); err != nil {
+			log.Printf("Payment record error: %v", err)
+			return
+		}
+		w.Queue.Ack(correlationID)
+		return
+	}
+
+	if err := w.Repository.Transition(correlationID, model.StateFailedRetryable); err != nil {
+		log.Printf("Payment state error: %v", err)
+		return
+	}
+	w.retry(dispatched.Request)
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"container/heap"
+	"math/rand/v2"
+	"rb2025-v3/model"
+	"sync"
+	"time"
+)
+
+type RetryPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	Jitter       float64
+	MaxAttempts  int
+	MaxAge       time.Duration
+}
+
+// Delay returns how long to wait before the next attempt of a payment that
+// has failed attempts times and was received age ago. It returns false once
+// the attempt or age budget is spent.
+func (p RetryPolicy) Delay(attempts int, age time.Duration) (time.Duration, bool) {
+	if p.MaxAttempts > 0 && attempts >= p.MaxAttempts {
+		return 0, false
+	}
+	delay := float64(p.InitialDelay)
+	for i := 1; i < attempts; i++ {
+		delay *= p.Multiplier
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if p.MaxAge > 0 && age+time.Duration(delay) > p.MaxAge {
+		return 0, false
+	}
+	return time.Duration(delay), true
+}
+
+type delayedJob struct {
+	Request model.PaymentRequest
+	Due     time.Time
+}
+
+type delayHeap []delayedJob
+
+func (h delayHeap) Len() int           { return len(h) }
+func (h delayHeap) Less(i, j int) bool { return h[i].Due.Before(h[j].Due) }
+func (h delayHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *delayHeap) Push(x any)        { *h = append(*h, x.(delayedJob)) }
+func (h *delayHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return job
+}
+
+// DelayQueue holds up to Capacity retries until they are due and then
+// releases them on Ready. Jobs count against the capacity until a worker
+// calls Done, so Ready never fills up and the timer goroutine never blocks.
+type DelayQueue struct {
+	Ready    chan model.PaymentRequest
+	Capacity int
+	mu       sync.Mutex
+	jobs     delayHeap
+	size     int
+	wake     chan struct{}
+}
+
+func NewDelayQueue(capacity int) *DelayQueue {
+	return &DelayQueue{
+		Ready:    make(chan model.PaymentRequest, capacity),
+		Capacity: capacity,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Schedule queues req for release after delay. It returns false when the
+// queue is at capacity.
+func (q *DelayQueue) Schedule(req model.PaymentRequest, delay time.Duration) bool {
+	q.mu.Lock()
+	if q.size >= q.Capacity {
+		q.mu.Unlock()
+		return false
+	}
+	q.size++
+	heap.Push(&q.jobs, delayedJob{Request: req, Due: time.Now().Add(delay)})
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Done releases the capacity held by a job taken from Ready.
+func (q *DelayQueue) Done() {
+	q.mu.Lock()
+	q.size--
+	q.mu.Unlock()
+}
+
+// Len returns the number of retries waiting or ready.
+func (q *DelayQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+func (q *DelayQueue) Start() {
+	go func() {
+		timer := time.NewTimer(time.Hour)
+		for {
+			q.mu.Lock()
+			now := time.Now()
+			for len(q.jobs) > 0 && !q.jobs[0].Due.After(now) {
+				q.Ready <- heap.Pop(&q.jobs).(delayedJob).Request
+			}
+			wait := time.Hour
+			if len(q.jobs) > 0 {
+				wait = q.jobs[0].Due.Sub(now)
+			}
+			q.mu.Unlock()
+
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-q.wake:
+				timer.Stop()
+			}
+		}
+	}()
+}
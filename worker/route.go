@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+)
+
+// PassivePolicy decides when live traffic overrides the polled health.
+type PassivePolicy struct {
+	MinSamples   int
+	MaxErrorRate float64
+}
+
+func (p PassivePolicy) degraded(stats client.Stats) bool {
+	return stats.Requests >= p.MinSamples && stats.ErrorRate() >= p.MaxErrorRate
+}
+
+// routing is what the health poller last learned. It is never modified once
+// published, so a payment routed with one value is recorded against it too.
+type routing struct {
+	Health model.ServiceHealthResponse
+}
+
+func (w *Worker) snapshot() Snapshot {
+	urls := w.Client.ProcessorUrls()
+	s := Snapshot{
+		Health:     w.routing.Load().Health,
+		Stats:      make([]client.Stats, len(urls)),
+		Breakers:   make([]client.BreakerState, len(urls)),
+		Fees:       w.Client.Fees(),
+		Priorities: make([]int, len(urls)),
+	}
+	for processor := range urls {
+		s.Stats[processor] = w.Client.Stats(processor)
+		s.Breakers[processor] = w.Client.BreakerState(processor)
+		s.Priorities[processor] = w.Client.Processors[processor].Priority
+	}
+	return s
+}
+
+// route takes the router's order and skips processors whose breaker is open
+// or whose live traffic shows them degrading, without waiting for the next
+// health poll. If every candidate is skipped the router's first pick is used.
+// The next usable processor in the order is returned as the hedge target, or
+// -1 when there is none.
+func (w *Worker) route(evt model.PaymentRequest) (int, int) {
+	s := w.snapshot()
+	order := w.Router.Route(s, evt)
+	if len(order) == 0 {
+		order = []int{0}
+	}
+	choice, hedge := -1, -1
+	for _, processor := range order {
+		if s.Breakers[processor] == client.BreakerOpen || w.Passive.degraded(s.Stats[processor]) {
+			continue
+		}
+		if choice < 0 {
+			choice = processor
+			continue
+		}
+		hedge = processor
+		break
+	}
+	if choice < 0 {
+		choice = order[0]
+	}
+	return choice, hedge
+}
@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"sort"
+	"time"
+)
+
+// Snapshot is what a Router sees of the processors when routing a payment.
+type Snapshot struct {
+	Health     model.ServiceHealthResponse
+	Stats      []client.Stats
+	Breakers   []client.BreakerState
+	Fees       []float64
+	Priorities []int
+}
+
+func (s Snapshot) Healthy(processor int) bool {
+	return healthy(s.Health, processor)
+}
+
+func (s Snapshot) MinResponse(processor int) time.Duration {
+	if processor < len(s.Health.Processors) {
+		return time.Duration(s.Health.Processors[processor].MinResponse) * time.Millisecond
+	}
+	switch processor {
+	case 0:
+		return time.Duration(s.Health.DefaultMinResponse) * time.Millisecond
+	case 1:
+		return time.Duration(s.Health.FallbackMinResponse) * time.Millisecond
+	}
+	return 0
+}
+
+// healthy reads a processor's polled health. Responses without the
+// per-processor list only know processors 0 and 1 as default and fallback.
+func healthy(health model.ServiceHealthResponse, processor int) bool {
+	if len(health.Processors) > 0 {
+		return processor < len(health.Processors) && health.Processors[processor].Healthy
+	}
+	switch processor {
+	case 0:
+		return health.DefaultHealth
+	case 1:
+		return health.FallbackHealth
+	}
+	return false
+}
+
+// Latency prefers what we observed ourselves and falls back to the polled
+// minimum response time.
+func (s Snapshot) Latency(processor int) time.Duration {
+	if stats := s.Stats[processor]; stats.Requests > 0 {
+		return stats.MeanLatency
+	}
+	return s.MinResponse(processor)
+}
+
+func (s Snapshot) healthy() []int {
+	var processors []int
+	for processor := range s.Stats {
+		if s.Healthy(processor) {
+			processors = append(processors, processor)
+		}
+	}
+	return processors
+}
+
+// Router orders the processors to try for a payment, best first. Processors
+// left out of the order are not tried.
+type Router interface {
+	Route(s Snapshot, payment model.PaymentRequest) []int
+}
+
+// PriorityFirst tries the healthy processors in order of their configured
+// priority, lowest first, so the default processor wins while it is up.
+type PriorityFirst struct{}
+
+func (PriorityFirst) Route(s Snapshot, payment model.PaymentRequest) []int {
+	order := s.healthy()
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.Priorities[order[i]] < s.Priorities[order[j]]
+	})
+	return order
+}
+
+// LowestLatency prefers the healthy processor that currently answers fastest.
+type LowestLatency struct{}
+
+func (LowestLatency) Route(s Snapshot, payment model.PaymentRequest) []int {
+	order := s.healthy()
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.Latency(order[i]) < s.Latency(order[j])
+	})
+	return order
+}
+
+// CostAware tries the healthy processors cheapest first, by fee and then
+// priority, but lets a dearer processor go ahead of a cheaper one that is
+// slower than it by at least Tolerance.
+type CostAware struct {
+	Tolerance time.Duration
+}
+
+func (r CostAware) Route(s Snapshot, payment model.PaymentRequest) []int {
+	order := s.healthy()
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if s.Fees[a] != s.Fees[b] {
+			return s.Fees[a] < s.Fees[b]
+		}
+		return s.Priorities[a] < s.Priorities[b]
+	})
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && s.MinResponse(order[j-1]) >= s.MinResponse(order[j])+r.Tolerance; j-- {
+			order[j-1], order[j] = order[j], order[j-1]
+		}
+	}
+	return order
+}
+
+// NetRevenue orders the healthy processors by the expected net revenue of a
+// payment: what is left after the fee, weighted by the chance the processor
+// accepts it, minus LatencyPenalty for every second it takes to answer.
+type NetRevenue struct {
+	LatencyPenalty float64
+}
+
+func (r NetRevenue) Route(s Snapshot, payment model.PaymentRequest) []int {
+	order := s.healthy()
+	scores := make(map[int]float64, len(order))
+	for _, processor := range order {
+		scores[processor] = r.expectedRevenue(s, processor, payment.Amount.Float64())
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	return order
+}
+
+func (r NetRevenue) expectedRevenue(s Snapshot, processor int, amount float64) float64 {
+	success := 1 - s.Stats[processor].ErrorRate()
+	net := amount * (1 - s.Fees[processor]) * success
+	return net - amount*r.LatencyPenalty*s.Latency(processor).Seconds()
+}
+
+// Weighted splits traffic between the healthy processors in proportion to
+// Weights, indexed by processor.
+type Weighted struct {
+	Weights []float64
+}
+
+func (r Weighted) Route(s Snapshot, payment model.PaymentRequest) []int {
+	order := s.healthy()
+	var total float64
+	for _, processor := range order {
+		total += r.weight(processor)
+	}
+	if total <= 0 {
+		return order
+	}
+	pick := rand.Float64() * total
+	for i, processor := range order {
+		pick -= r.weight(processor)
+		if pick < 0 {
+			order[0], order[i] = order[i], order[0]
+			break
+		}
+	}
+	return order
+}
+
+func (r Weighted) weight(processor int) float64 {
+	if processor < len(r.Weights) {
+		return r.Weights[processor]
+	}
+	return 0
+}
+
+// NewRouter builds the routing strategy called name.
+func NewRouter(name string, tolerance time.Duration, latencyPenalty float64, weights []float64) (Router, error) {
+	switch name {
+	case "priority", "default-first":
+		return PriorityFirst{}, nil
+	case "lowest-latency":
+		return LowestLatency{}, nil
+	case "cost-aware":
+		return CostAware{Tolerance: tolerance}, nil
+	case "net-revenue":
+		return NetRevenue{LatencyPenalty: latencyPenalty}, nil
+	case "weighted":
+		return Weighted{Weights: weights}, nil
+	}
+	return nil, fmt.Errorf("unknown router %q", name)
+}
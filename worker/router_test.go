@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCostAwareRoute(t *testing.T) {
+	processors := func(minResponses ...int) model.ServiceHealthResponse {
+		var health model.ServiceHealthResponse
+		for _, ms := range minResponses {
+			health.Processors = append(health.Processors, model.ProcessorHealth{Healthy: true, MinResponse: ms})
+		}
+		return health
+	}
+	for _, tc := range []struct {
+		name       string
+		health     model.ServiceHealthResponse
+		fees       []float64
+		priorities []int
+		want       []int
+	}{
+		{"cheapest first", processors(10, 10, 10), []float64{0.15, 0.05, 0.10}, []int{0, 1, 2}, []int{1, 2, 0}},
+		{"priority breaks fee ties", processors(10, 10), []float64{0.05, 0.05}, []int{1, 0}, []int{1, 0}},
+		{"slow within tolerance", processors(10, 50), []float64{0.15, 0.05}, []int{0, 1}, []int{1, 0}},
+		{"slow beyond tolerance", processors(10, 200), []float64{0.15, 0.05}, []int{0, 1}, []int{0, 1}},
+		{"slowest of three", processors(200, 10, 20), []float64{0.05, 0.10, 0.15}, []int{0, 1, 2}, []int{1, 2, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Snapshot{
+				Health:     tc.health,
+				Stats:      make([]client.Stats, len(tc.fees)),
+				Fees:       tc.fees,
+				Priorities: tc.priorities,
+			}
+			got := CostAware{Tolerance: 100 * time.Millisecond}.Route(s, model.PaymentRequest{})
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("Route = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,113 +1,282 @@
 package worker
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"rb2025-v3/client"
 	"rb2025-v3/model"
+	"rb2025-v3/queue"
 	"rb2025-v3/repository"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Worker struct {
-	Jobs             chan model.PaymentRequest
-	Repository       *repository.Repository
-	Client           *client.Client
-	NumWorkers       int
-	DefaultTolerance int
-	Suspended        bool
-	ProcessorUrl     string
-	Processor        int
-	WorkerSleep      int
-	SuspendedCh      chan struct{}
-	Semaphore        chan struct{}
+	Queue          *queue.Queue
+	Repository     *repository.Repository
+	DeadLetters    *repository.DeadLetterStore
+	Client         *client.Client
+	NumWorkers     int
+	Router         Router
+	ReconcileEvery time.Duration
+	RetryPolicy    RetryPolicy
+	Retries        *DelayQueue
+	Passive        PassivePolicy
+	Hedge          HedgePolicy
+	Deadline       DeadlinePolicy
+	quit           chan struct{}
+	// routing is replaced as a whole by the health poller and read by every
+	// worker goroutine.
+	routing atomic.Pointer[routing]
+	// suspended holds the worker goroutines back while no processor is
+	// healthy.
+	suspended *gate
+	// ctx is cancelled when Stop gives up waiting. Payments waiting for a
+	// limiter slot, a settle or a retry give up at once; posts already sent
+	// are not interrupted and end within their attempt deadline.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	running  sync.WaitGroup
+	inFlight atomic.Int64
 }
 
-func NewWorker(jobs chan model.PaymentRequest, r *repository.Repository, c *client.Client, numWorkers, defaultTolerance, semaphoreSize, workerSleep int) *Worker {
-	return &Worker{
-		Jobs:         jobs,
-		Repository:   r,
-		Client:       c,
-		NumWorkers:   numWorkers,
-		Suspended:    false,
-		ProcessorUrl: c.DefaultUrl,
-		Processor:    0,
-		SuspendedCh:  make(chan struct{}),
-		Semaphore:    make(chan struct{}, semaphoreSize),
+// StopReport counts what happened to outstanding payments during Stop.
+type StopReport struct {
+	// Drained payments were in flight when Stop was called and finished.
+	Drained int
+	// Persisted payments were still queued and remain in the on-disk spool.
+	Persisted int
+	// Abandoned payments were lost without a spool, or were still in flight
+	// when Stop gave up, so whether their processor recorded them is unknown.
+	// With a durable spool those are still in it and reconciled on restart.
+	Abandoned int
+}
+
+func NewWorker(q *queue.Queue, r *repository.Repository, dl *repository.DeadLetterStore, c *client.Client, router Router, numWorkers int, reconcileEvery time.Duration, retryPolicy RetryPolicy, retryQueueSize int, passive PassivePolicy) *Worker {
+	w := &Worker{
+		Queue:          q,
+		Repository:     r,
+		DeadLetters:    dl,
+		Client:         c,
+		NumWorkers:     numWorkers,
+		Router:         router,
+		ReconcileEvery: reconcileEvery,
+		RetryPolicy:    retryPolicy,
+		Passive:        passive,
 	}
+	w.Retries = NewDelayQueue(retryQueueSize)
+	w.quit = make(chan struct{})
+	w.routing.Store(&routing{})
+	w.suspended = newGate()
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	return w
 }
 
 func (w *Worker) handleEvent(evt model.PaymentRequest) {
-	w.Semaphore <- struct{}{}
+	if _, ok := w.Repository.Payments.Load(evt.CorrelationID); ok {
+		// Recorded before a crash but never acknowledged.
+		w.Repository.Settle(evt.CorrelationID)
+		w.Queue.Ack(evt.CorrelationID)
+		return
+	}
+	if state, _ := w.Repository.State(evt.CorrelationID); state == model.StateUnknownOutcome {
+		// Replayed after a restart; the reconciler sends it again only if no
+		// processor has it.
+		return
+	}
+	w.inFlight.Add(1)
+	defer w.inFlight.Add(-1)
+	processor, hedge := w.route(evt)
 	requestedAt := time.Now().UTC()
+	err := w.Repository.Dispatch(evt.CorrelationID, processor, requestedAt)
+	if errors.Is(err, repository.ErrNotTracked) {
+		// Still spooled, so it was accepted; track it again and send it.
+		w.Repository.Reserve(evt)
+		err = w.Repository.Dispatch(evt.CorrelationID, processor, requestedAt)
+	}
+	if err != nil {
+		log.Printf("Skipping job: %v", err)
+		w.Queue.Ack(evt.CorrelationID)
+		return
+	}
 	requestedAtStr := requestedAt.Format(time.RFC3339Nano)
 	paymentEvent := model.PaymentEvent{
 		CorrelationID: evt.CorrelationID,
 		Amount:        evt.Amount,
+		Currency:      evt.Currency,
 		RequestedAt:   requestedAtStr,
 	}
-	if w.Client.PostJSON(w.ProcessorUrl, paymentEvent) {
+	processor, outcome := w.post(processor, hedge, paymentEvent)
+	switch outcome {
+	case client.OutcomeSuccess:
 		payment := model.Payment{
 			CorrelationID: evt.CorrelationID,
 			Amount:        evt.Amount,
-			Processor:     w.Processor,
+			Currency:      evt.Currency,
+			Processor:     processor,
 			RequestedAt:   requestedAt,
 		}
-		w.Repository.Add(payment)
-	} else {
-		w.Jobs <- evt
+		if err := w.Repository.Add(payment); err != nil {
+			log.Printf("Payment record error: %v", err)
+			if errors.Is(err, repository.ErrNotLogged) {
+				// Left in the spool so it is reconciled after a restart.
+				return
+			}
+		}
+		w.Queue.Ack(evt.CorrelationID)
+	case client.OutcomeRetryable, client.OutcomeOpen:
+		w.transition(evt.CorrelationID, model.StateFailedRetryable)
+		w.retry(evt)
+	case client.OutcomeRejected:
+		w.deadLetter(evt, "rejected by processor")
+	case client.OutcomeUnknown:
+		// Left pending in the spool; sending it again could charge twice.
+		w.transition(evt.CorrelationID, model.StateUnknownOutcome)
+	}
+}
+
+// retry schedules a failed payment for another attempt according to the
+// retry policy, or marks it dead once the policy gives up on it.
+func (w *Worker) retry(req model.PaymentRequest) {
+	attempts, receivedAt, _ := w.Repository.Attempts(req.CorrelationID)
+	delay, ok := w.RetryPolicy.Delay(attempts, time.Since(receivedAt))
+	if !ok {
+		w.deadLetter(req, fmt.Sprintf("gave up after %d attempts", attempts))
+		return
+	}
+	if !w.Retries.Schedule(req, delay) {
+		w.deadLetter(req, "retry queue full")
+	}
+}
+
+// deadLetter marks a payment dead and parks it for operators to replay or
+// discard.
+func (w *Worker) deadLetter(req model.PaymentRequest, reason string) {
+	attempts, _, _ := w.Repository.Attempts(req.CorrelationID)
+	w.transition(req.CorrelationID, model.StateDead)
+	err := w.DeadLetters.Add(model.DeadLetter{
+		CorrelationID: req.CorrelationID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Reason:        reason,
+		Attempts:      attempts,
+		DeadAt:        time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		// Left in the spool so it is not lost with the letter.
+		log.Printf("Dead letter record error: %v", err)
+		return
+	}
+	w.Queue.Ack(req.CorrelationID)
+}
+
+func (w *Worker) transition(correlationID string, to model.PaymentState) {
+	if err := w.Repository.Transition(correlationID, to); err != nil {
+		log.Printf("Payment state error: %v", err)
 	}
-	<-w.Semaphore
-	time.Sleep(time.Duration(w.WorkerSleep) * time.Millisecond)
 }
 
 func (w *Worker) worker() {
+	defer w.running.Done()
 	for {
-		if w.Suspended {
-			<-w.SuspendedCh
+		if !w.suspended.Wait() {
+			return
+		}
+		// A select picks at random among ready cases, so quit is checked on
+		// its own before any work is taken.
+		select {
+		case <-w.quit:
+			return
+		default:
 		}
-		evt := <-w.Jobs
-		w.handleEvent(evt)
+		// Retries go first so a backlog of new payments cannot starve them.
+		select {
+		case evt := <-w.Retries.Ready:
+			w.Retries.Done()
+			w.handleEvent(evt)
+			continue
+		default:
+		}
+		select {
+		case <-w.quit:
+			return
+		case evt := <-w.Retries.Ready:
+			w.Retries.Done()
+			w.handleEvent(evt)
+		case evt := <-w.Queue.Jobs:
+			w.handleEvent(evt)
+		}
+	}
+}
+
+// Stop stops taking new work and waits until the in-flight payments finish or
+// ctx is done. Queued payments are left in the spool when it is durable. Stop
+// does not wait for posts still on the wire once ctx is done.
+func (w *Worker) Stop(ctx context.Context) StopReport {
+	close(w.quit)
+	w.suspended.Stop()
+	inFlight := w.inFlight.Load()
+	done := make(chan struct{})
+	go func() {
+		w.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.cancel()
+	}
+
+	remaining := w.inFlight.Load()
+	report := StopReport{Drained: int(inFlight - remaining), Abandoned: int(remaining)}
+	queued := len(w.Queue.Jobs) + w.Retries.Len()
+	if w.Queue.Log != nil {
+		report.Persisted = queued
+	} else {
+		report.Abandoned += queued
 	}
+	log.Printf("Worker stopped: %d drained, %d persisted, %d abandoned", report.Drained, report.Persisted, report.Abandoned)
+	return report
+}
+
+func (w *Worker) anyHealthy(health model.ServiceHealthResponse) bool {
+	for processor := range w.Client.Processors {
+		if healthy(health, processor) {
+			return true
+		}
+	}
+	return false
 }
 
 func (w *Worker) Start() {
 
+	w.Retries.Start()
+	w.running.Add(w.NumWorkers)
 	for i := 0; i < w.NumWorkers; i += 1 {
 		go w.worker()
 	}
 
+	if w.ReconcileEvery > 0 {
+		go w.reconciler()
+	}
+
 	go func() {
 		for {
-			health, err := w.Client.ServiceHealth()
+			health, err := w.Client.ServiceHealth(w.ctx)
 			if err != nil {
 				time.Sleep(500 * time.Millisecond)
 			}
-			wasSuspended := w.Suspended
-			w.Suspended = false
-			if health.DefaultHealth && health.FallbackHealth {
-				if health.DefaultMinResponse < (health.FallbackMinResponse + 1000) {
-					w.ProcessorUrl = w.Client.DefaultUrl
-					w.Processor = 0
-				} else {
-					w.ProcessorUrl = w.Client.FallbackUrl
-					w.Processor = 1
-				}
-			} else if health.DefaultHealth {
-				w.ProcessorUrl = w.Client.DefaultUrl
-				w.Processor = 0
-			} else if health.FallbackHealth {
-				w.ProcessorUrl = w.Client.FallbackUrl
-				w.Processor = 1
-			} else {
-				if !wasSuspended {
+			suspended := !w.anyHealthy(health)
+			w.routing.Store(&routing{Health: health})
+			if w.suspended.Set(suspended) {
+				if suspended {
 					log.Println("Suspend jobs")
+				} else {
+					log.Println("Resume jobs")
 				}
-				w.Suspended = true
-			}
-			if wasSuspended && !w.Suspended {
-				log.Println("Resume jobs")
-				close(w.SuspendedCh)
-				w.SuspendedCh = make(chan struct{})
 			}
 			time.Sleep(time.Duration(health.NextCheck+50) * time.Millisecond)
 		}
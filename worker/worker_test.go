@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"rb2025-v3/client"
+	"rb2025-v3/model"
+	"rb2025-v3/queue"
+	"rb2025-v3/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+var healthyHealth = model.ServiceHealthResponse{DefaultHealth: true, FallbackHealth: true, NextCheck: 10}
+
+// newTestWorker returns a worker whose processors and health endpoint are
+// served by an httptest server that takes delay to accept each payment.
+func newTestWorker(t *testing.T, delay time.Duration, numWorkers int) *Worker {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"defaultHeath":true,"fallbackHealth":true,"defaultMinResponse":5,"fallbackMinResponse":5,"nextCheck":10}`)
+	})
+	mux.HandleFunc("/payments", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	processors := []client.Processor{{Name: "default", Url: server.URL}, {Name: "fallback", Url: server.URL}}
+	c, err := client.NewClient(processors, server.URL, time.Second, client.BreakerSettings{}, client.LimiterSettings{Initial: 100, Max: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := queue.NewQueue(1000, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := repository.NewRepository(nil, time.Minute, false, c.ProcessorNames())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl, err := repository.NewDeadLetterStore(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorker(q, r, dl, c, PriorityFirst{}, numWorkers, 0, RetryPolicy{MaxAttempts: 1}, 100, PassivePolicy{MinSamples: 1000})
+	w.routing.Store(&routing{Health: healthyHealth})
+	return w
+}
+
+func submit(t *testing.T, w *Worker, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		req := model.PaymentRequest{CorrelationID: fmt.Sprintf("payment-%d", i), Amount: 1000}
+		w.Repository.Reserve(req)
+		if !w.Queue.Push(req) {
+			t.Fatalf("Push %d refused", i)
+		}
+	}
+}
+
+func stored(w *Worker) int {
+	n := 0
+	w.Repository.Payments.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func TestRoutingPublish(t *testing.T) {
+	w := newTestWorker(t, 0, 1)
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			health := healthyHealth
+			health.DefaultHealth = i%2 == 0
+			w.routing.Store(&routing{Health: health})
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				s := w.snapshot()
+				if !s.Healthy(0) && !s.Healthy(1) {
+					t.Error("snapshot saw no healthy processor")
+					return
+				}
+				if processor, _ := w.route(model.PaymentRequest{CorrelationID: "x", Amount: 1000}); processor < 0 {
+					t.Error("route found no processor")
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+func TestWorkerStopDrainsInFlight(t *testing.T) {
+	w := newTestWorker(t, 50*time.Millisecond, 10)
+	submit(t, w, 100)
+	w.Start()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	report := w.Stop(ctx)
+	if report.Drained == 0 {
+		t.Errorf("no payments drained: %+v", report)
+	}
+	if report.Persisted != 0 {
+		t.Errorf("payments persisted without a spool: %+v", report)
+	}
+	if got := stored(w) + report.Abandoned; got != 100 {
+		t.Errorf("stored %d + abandoned %d = %d, want 100", stored(w), report.Abandoned, got)
+	}
+}
+
+func TestWorkerStopCutsOffAtDeadline(t *testing.T) {
+	w := newTestWorker(t, time.Second, 10)
+	submit(t, w, 20)
+	w.Start()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report := w.Stop(ctx)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Stop took %v past a 100ms deadline", elapsed)
+	}
+	if report.Abandoned != 20 {
+		t.Errorf("abandoned %d, want 20: %+v", report.Abandoned, report)
+	}
+}
+
+func TestWorkerTakesNoWorkAfterQuit(t *testing.T) {
+	w := newTestWorker(t, 0, 1)
+	submit(t, w, 20)
+	for i := 0; i < 20; i++ {
+		w.Retries.Ready <- model.PaymentRequest{CorrelationID: fmt.Sprintf("retry-%d", i), Amount: 1000}
+	}
+	close(w.quit)
+	w.running.Add(1)
+	w.worker()
+	if len(w.Queue.Jobs) != 20 || len(w.Retries.Ready) != 20 {
+		t.Fatalf("worker took work after quit: %d jobs and %d retries left, want 20 each", len(w.Queue.Jobs), len(w.Retries.Ready))
+	}
+}